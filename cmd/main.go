@@ -1,59 +1,85 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"io"
 	"os"
 
 	"github.com/finiteloopme/goutils/pkg/codegen"
 	"github.com/finiteloopme/goutils/pkg/log"
+	"github.com/finiteloopme/goutils/pkg/v2/os/env"
 )
 
 const (
 	CREATE_APP string = "create-app"
+	CONFIG_DOC string = "config-doc"
 )
 
-func main() {
+// Flags of the create-app subcommand
+type createAppConfig struct {
+	Type     string `flag:"type" default:"go-simple" oneof:"go-simple go-cloudrun go-grpc" description:"Application type"`
+	Name     string `flag:"name" required:"true" description:"Application name"`
+	FqdnName string `flag:"fqdn-name" description:"Fully qualified module name to use with 'go mod init'"`
+	Output   string `flag:"output" default:"${Name}" expand:"true" description:"Folder name to host the app. Defaults to the app name"`
+}
+
+// Flags of the config-doc subcommand
+type configDocConfig struct {
+	Pkg    string `flag:"pkg" default:"." description:"Folder of the Go package declaring the config struct"`
+	Type   string `flag:"type" default:"Config" description:"Name of the config struct"`
+	Prefix string `flag:"prefix" description:"Environment variable prefix passed to env.ProcessConfig"`
+	Format string `flag:"format" default:"markdown" oneof:"usage markdown dotenv yaml" description:"Output format"`
+	Output string `flag:"output" description:"File to write to.  Defaults to stdout"`
+}
 
-	if len(os.Args) < 2 {
+func main() {
+	var createApp createAppConfig
+	var configDoc configDocConfig
+	// The CLI runs in arbitrary directories: don't read their .env files or a profile.
+	subcommand, _, err := env.ProcessSubcommand(context.Background(), "", os.Args[1:], map[string]interface{}{
+		CREATE_APP: &createApp,
+		CONFIG_DOC: &configDoc,
+	}, env.WithDotenvDisabled(), env.WithProfileEnv(""))
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Warn("Invalid arguments. ", err)
 		printUsage()
 		os.Exit(1)
 	}
-	switch os.Args[1] {
+	switch subcommand {
 	case CREATE_APP:
-		ProcessCreateApp()
-	default:
-		printUsage()
-		os.Exit(1)
+		ProcessCreateApp(createApp)
+	case CONFIG_DOC:
+		ProcessConfigDoc(configDoc)
 	}
 }
 
 func printUsage() {
 	usageMessage := "usage:" +
-		"goutils create-app --type go-simple --name app-name --fqdn-name module-repo --output app-name"
+		"goutils create-app --type go-simple --name app-name --fqdn-name module-repo --output app-name\n" +
+		"       goutils config-doc --pkg ./internal/config --type Config --format markdown --output CONFIG.md"
 	log.Info(usageMessage)
 	return
 }
 
-func ProcessCreateApp() {
-	createAppCmd := flag.NewFlagSet(CREATE_APP, flag.ExitOnError)
-	appType := createAppCmd.String("type", "go-simple", "Application type.  Only 'go-simple', 'go-cloudrun' or 'go-grpc' is supported")
-	appName := createAppCmd.String("name", "", "Application name")
-	fqdnName := createAppCmd.String("fqdn-name", "", "Fully qualified module name to use with `go mod init`")
-	appLocation := createAppCmd.String("output", *appName, "Folder name to host the app")
-	createAppCmd.Parse((os.Args[2:]))
-	switch *appType {
+func ProcessCreateApp(config createAppConfig) {
+	switch config.Type {
 	case "go-simple":
 		// Example
 		// goutils create-app --name simple-app --type go-simple --fqdn-name github.com/finiteloopme/demo/simple-app --output simple-app
-		codegen.NewSimpleGoModule(*appName, *fqdnName, *appLocation)
+		codegen.NewSimpleGoModule(config.Name, config.FqdnName, config.Output)
 	case "go-cloudrun":
 		// Example
 		// goutils create-app --name cloudrun-app --type go-cloudrun --fqdn-name github.com/finiteloopme/demo/cloudrun-app --output cloudrun-app
-		codegen.NewCloudRunGoModule(*appName, *fqdnName, *appLocation)
+		codegen.NewCloudRunGoModule(config.Name, config.FqdnName, config.Output)
 	case "go-grpc":
 		// Example
 		// goutils create-app --name grpc-app --type go-grpc --fqdn-name github.com/finiteloopme/demo/grpc-app --output grpc-app
-		codegen.NewGRPCGoModule(*appName, *fqdnName, *appLocation)
+		codegen.NewGRPCGoModule(config.Name, config.FqdnName, config.Output)
 	default:
 		printUsage()
 		os.Exit(1)
@@ -61,3 +87,27 @@ func ProcessCreateApp() {
 
 	return
 }
+
+func ProcessConfigDoc(config configDocConfig) {
+	// Example
+	// goutils config-doc --pkg ./internal/config --type Config --format dotenv --output .env.example
+
+	var w io.Writer = os.Stdout
+	if config.Output != "" {
+		f, err := os.Create(config.Output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	spec := codegen.ConfigDocSpec{
+		PackageDir: config.Pkg,
+		TypeName:   config.Type,
+		Prefix:     config.Prefix,
+		Format:     config.Format,
+	}
+	if err := codegen.GenerateConfigDoc(spec, w); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -7,9 +7,9 @@ toolchain go1.24.2
 require (
 	cloud.google.com/go/compute/metadata v0.6.0
 	cloud.google.com/go/secretmanager v1.14.7
+	github.com/BurntSushi/toml v1.5.0
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.11.3
 	github.com/joho/godotenv v1.5.1
-	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.10.0
 	google.golang.org/grpc v1.71.1
@@ -0,0 +1,109 @@
+// Generate documentation for v2 env config structs
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// ConfigDocSpec identifies a config struct and how to document it.
+type ConfigDocSpec struct {
+	// Directory of the Go package declaring the struct
+	PackageDir string
+	// Import path of the package. Resolved from PackageDir if empty
+	ImportPath string
+	// Name of the struct type
+	TypeName string
+	// Environment variable prefix, as passed to env.ProcessConfig
+	Prefix string
+	// One of the env.DocFormat* values
+	Format string
+}
+
+// GenerateConfigDoc writes the documentation of a config struct to w, rendered by env.WriteDoc.
+// Struct tags are only available through reflection, so a small program importing the
+// package is generated in a temporary directory and run in a workspace with the package's
+// module; the module must depend on goutils.
+func GenerateConfigDoc(spec ConfigDocSpec, w io.Writer) error {
+	if spec.TypeName == "" {
+		return fmt.Errorf("config type name is required")
+	}
+	if spec.PackageDir == "" {
+		spec.PackageDir = "."
+	}
+	if spec.ImportPath == "" {
+		out, err := runGo(spec.PackageDir, nil, "list", "-f", "{{.ImportPath}}", ".")
+		if err != nil {
+			return err
+		}
+		spec.ImportPath = strings.TrimSpace(out)
+	}
+
+	tmpl, err := template.ParseFS(templatesFS, "template/configdoc/main.go_template")
+	if err != nil {
+		return err
+	}
+	// Generate the program in a module of its own, outside the package's source tree,
+	// and run it in a workspace with the package's module so that it resolves the same dependencies.
+	out, err := runGo(spec.PackageDir, nil, "list", "-m", "-f", "{{.Dir}}\n{{.GoVersion}}")
+	if err != nil {
+		return err
+	}
+	moduleDir, goVersion, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	if goVersion == "" {
+		goVersion = "1.23"
+	}
+	dir, err := os.MkdirTemp("", "config-doc-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"go.mod":  fmt.Sprintf("module config-doc\n\ngo %s\n", goVersion),
+		"go.work": fmt.Sprintf("go %s\n\nuse (\n\t.\n\t%q\n)\n", goVersion, moduleDir),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(filepath.Join(dir, "main.go"))
+	if err != nil {
+		return err
+	}
+	err = tmpl.Execute(f, spec)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Workspaces only allow -mod=readonly, which GOFLAGS may override.
+	out, err = runGo(dir, []string{"GOWORK=" + filepath.Join(dir, "go.work"), "GOFLAGS=-mod=readonly"}, "run", ".")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+// runGo runs the go command in dir, with env added to the environment, and returns its standard output.
+func runGo(dir string, env []string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("go %s: %v\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.String(), nil
+}
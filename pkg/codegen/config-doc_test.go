@@ -0,0 +1,28 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateConfigDoc(t *testing.T) {
+	var out strings.Builder
+	spec := ConfigDocSpec{
+		PackageDir: "testdata/configdoc",
+		TypeName:   "Config",
+		Prefix:     "APP_",
+		Format:     "dotenv",
+	}
+	if err := GenerateConfigDoc(spec, &out); err != nil {
+		t.Fatalf("Error generating config doc. Error: (%v)", err)
+	}
+	expected := "# Port (int): Port to listen on\nAPP_PORT=8080\n\n# Name (string)\n# required\nAPP_NAME=\n"
+	if out.String() != expected {
+		t.Fatalf("Config doc expected to be:\n%v\nreceived:\n%v", expected, out.String())
+	}
+
+	spec.TypeName = "Missing"
+	if err := GenerateConfigDoc(spec, &out); err == nil {
+		t.Fatalf("Expected an error for an unknown type")
+	}
+}
@@ -5,12 +5,13 @@
 package codegen
 
 import (
+	"context"
 	"embed"
 	"text/template"
 
 	"github.com/finiteloopme/goutils/pkg/io"
 	log "github.com/finiteloopme/goutils/pkg/log"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/finiteloopme/goutils/pkg/v2/os/env"
 )
 
 type FSType string
@@ -110,14 +111,22 @@ type ProjectStructure struct {
 	BufWorkYaml              BufWorkYamlStructure
 }
 
+// Returns a project structure with the defaults of its fields, which can be
+// overridden by environment variables (e.g. OUT_FOLDERNAME=build)
+func newProjectStructure() ProjectStructure {
+	var projStruct ProjectStructure
+	if err := env.NewEnvconfigLoader().Load(context.Background(), "", &projStruct); err != nil {
+		log.Fatal(err)
+	}
+	return projStruct
+}
+
 // Create a go module with the given name
 // moduleName: the name of the go module
 // fullyQualifiedModuleName: fully qualified name for the module
 // outputDir: Output folder
 func NewSimpleGoModule(moduleName string, fullyQualifiedModuleName string, outputDir string) ProjectStructure {
-	var projStruct ProjectStructure
-	// var err error
-	envconfig.Process("", &projStruct)
+	projStruct := newProjectStructure()
 
 	// Projectname
 	projStruct.Projectname = moduleName
@@ -152,9 +161,7 @@ func NewSimpleGoModule(moduleName string, fullyQualifiedModuleName string, outpu
 }
 
 func NewCloudRunGoModule(moduleName string, fullyQualifiedModuleName string, outputDir string) ProjectStructure {
-	var projStruct ProjectStructure
-	// var err error
-	envconfig.Process("", &projStruct)
+	projStruct := newProjectStructure()
 
 	// Projectname
 	projStruct.Projectname = moduleName
@@ -189,9 +196,7 @@ func NewCloudRunGoModule(moduleName string, fullyQualifiedModuleName string, out
 }
 
 func NewGRPCGoModule(moduleName string, fullyQualifiedModuleName string, outputDir string) ProjectStructure {
-	var projStruct ProjectStructure
-	// var err error
-	envconfig.Process("", &projStruct)
+	projStruct := newProjectStructure()
 
 	// Projectname
 	projStruct.Projectname = moduleName
@@ -0,0 +1,7 @@
+// Package configdoc declares a config struct used to test GenerateConfigDoc.
+package configdoc
+
+type Config struct {
+	Port int    `env:"PORT" flag:"port" default:"8080" description:"Port to listen on"`
+	Name string `env:"NAME" required:"true"`
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 
 	log "github.com/finiteloopme/goutils/pkg/log"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/finiteloopme/goutils/pkg/v2/os/env"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -53,7 +53,9 @@ func RunGRPCAndREST(service InterfaceGRPCWithHTTPHandler) error {
 	go RunGRPC(service)
 
 	var config GRPCConfig
-	envconfig.Process("gcp", &config)
+	if err := env.NewEnvconfigLoader().Load(context.Background(), "gcp", &config); err != nil {
+		return fmt.Errorf("Failed to load gRPC config: %w", err)
+	}
 	StartHTTPProxy(service, config)
 
 	return nil
@@ -5,11 +5,12 @@
 package grpc
 
 import (
+	"context"
 	"fmt"
 	"net"
 
 	log "github.com/finiteloopme/goutils/pkg/log"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/finiteloopme/goutils/pkg/v2/os/env"
 	"google.golang.org/grpc"
 )
 
@@ -37,7 +38,9 @@ type GRPCConfig struct {
 // Start the gRPC server
 func RunGRPC(service InterfaceGRPC) error {
 	var config GRPCConfig
-	envconfig.Process("gcp", &config)
+	if err := env.NewEnvconfigLoader().Load(context.Background(), "gcp", &config); err != nil {
+		return fmt.Errorf("Failed to load gRPC config: %w", err)
+	}
 	listenOn := config.GRPC_Host + ":" + config.GRPC_Port
 	listener, err := net.Listen("tcp", listenOn)
 	if err != nil {
@@ -5,12 +5,13 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 
 	log "github.com/finiteloopme/goutils/pkg/log"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/finiteloopme/goutils/pkg/v2/os/env"
 )
 
 // Config for HTTP Server
@@ -19,12 +20,17 @@ type HTTPConfig struct {
 	Host string `default:"0.0.0.0"`
 	// Set env variable GCP_PORT. Default value is 8080
 	Port string `default:"8080"`
+	// Set env variable GCP_LOGLEVELPATH (e.g. /debug/loglevel) to serve the handler reading and
+	// changing the log level (see log.LevelHandler). Disabled by default, as the handler is unauthenticated
+	LogLevelPath string
 }
 
 // Start the HTTP Server
 func StartHTTPServer() {
 	var config HTTPConfig
-	envconfig.Process("gcp", &config)
+	if err := env.NewEnvconfigLoader().Load(context.Background(), "gcp", &config); err != nil {
+		log.Fatal(err)
+	}
 	// Check if ./index.html exists
 	if _, err := os.Stat("./index.html"); os.IsNotExist(err) {
 		// ./index.html doesn't exist
@@ -34,6 +40,9 @@ func StartHTTPServer() {
 		// ./index.html exists. So serve the current directory
 		http.Handle("/", http.FileServer(http.Dir("./")))
 	}
+	if config.LogLevelPath != "" {
+		http.Handle(config.LogLevelPath, log.LevelHandler())
+	}
 	listenAt := config.Host + ":" + config.Port
 	log.Info("Server listening at: " + listenAt)
 	http.ListenAndServe(listenAt, nil)
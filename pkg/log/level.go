@@ -0,0 +1,238 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Log levels, from the most to the least verbose, accepted by SetLevel and `LOG_LEVEL`
+const (
+	LogLevelTrace = "trace"
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+	LogLevelFatal = "fatal"
+	// Default level, used when `LOG_LEVEL` is not set
+	LogLevelDefault = LogLevelInfo
+)
+
+// levels holds the level of the default logger and the level overrides of named loggers
+var levels = struct {
+	sync.RWMutex
+	root  log.Level
+	named map[string]log.Level
+}{root: log.InfoLevel, named: map[string]log.Level{}}
+
+// Configure log levels based on `LOG_LEVEL` variable
+func init() {
+	spec := os.Getenv("LOG_LEVEL")
+	if spec == "" {
+		spec = LogLevelDefault
+	}
+	if err := SetLevels(spec); err != nil {
+		log.Warnf("ignoring LOG_LEVEL: %v", err)
+		SetLevels(LogLevelDefault)
+	}
+}
+
+// parseLevel converts a level name (e.g. "debug") into a logrus level
+func parseLevel(level string) (log.Level, error) {
+	lvl, err := log.ParseLevel(strings.TrimSpace(level))
+	if err != nil || lvl == log.PanicLevel {
+		return 0, fmt.Errorf("invalid log level %q (expected one of trace, debug, info, warn, error or fatal)", level)
+	}
+	return lvl, nil
+}
+
+// levelName returns the name of a level as accepted by SetLevel
+func levelName(lvl log.Level) string {
+	if lvl == log.WarnLevel {
+		return LogLevelWarn
+	}
+	return lvl.String()
+}
+
+// applyLevels sets the level of the logrus logger to the most verbose configured level,
+// so that entries of named loggers with a more verbose override aren't dropped.
+// Callers must hold levels.
+func applyLevels() {
+	lvl := levels.root
+	for _, named := range levels.named {
+		if named > lvl {
+			lvl = named
+		}
+	}
+	log.SetLevel(lvl)
+}
+
+// Set the level of the default logger, and of named loggers without an override
+func SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	levels.Lock()
+	defer levels.Unlock()
+	levels.root = lvl
+	applyLevels()
+	return nil
+}
+
+// Level returns the level of the default logger
+func Level() string {
+	levels.RLock()
+	defer levels.RUnlock()
+	return levelName(levels.root)
+}
+
+// Set the level of the logger named name, and of the loggers nested under it
+// (e.g. "codegen" also applies to "codegen.template"). An empty level removes the override.
+func SetNamedLevel(name, level string) error {
+	if name == "" {
+		return SetLevel(level)
+	}
+	levels.Lock()
+	defer levels.Unlock()
+	if level == "" {
+		delete(levels.named, name)
+	} else {
+		lvl, err := parseLevel(level)
+		if err != nil {
+			return err
+		}
+		levels.named[name] = lvl
+	}
+	applyLevels()
+	return nil
+}
+
+// NamedLevel returns the effective level of the logger named name: its own override,
+// else the override of the closest enclosing name, else the level of the default logger
+func NamedLevel(name string) string {
+	levels.RLock()
+	defer levels.RUnlock()
+	return levelName(effectiveLevel(name))
+}
+
+// NamedLevels returns the level overrides of named loggers
+func NamedLevels() map[string]string {
+	levels.RLock()
+	defer levels.RUnlock()
+	named := make(map[string]string, len(levels.named))
+	for name, lvl := range levels.named {
+		named[name] = levelName(lvl)
+	}
+	return named
+}
+
+// SetLevels configures levels from a `LOG_LEVEL` style spec: a comma separated list of
+// a level for the default logger and name=level overrides (e.g. "info,codegen=debug").
+// Overrides not listed in spec are removed.
+func SetLevels(spec string) error {
+	root := log.InfoLevel
+	named := map[string]log.Level{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, level, isNamed := strings.Cut(part, "=")
+		if !isNamed {
+			level = name
+		}
+		lvl, err := parseLevel(level)
+		if err != nil {
+			return err
+		}
+		if isNamed {
+			named[strings.TrimSpace(name)] = lvl
+		} else {
+			root = lvl
+		}
+	}
+	levels.Lock()
+	defer levels.Unlock()
+	levels.root, levels.named = root, named
+	applyLevels()
+	return nil
+}
+
+// effectiveLevel returns the level of the logger named name. Callers must hold levels.
+func effectiveLevel(name string) log.Level {
+	for name != "" {
+		if lvl, ok := levels.named[name]; ok {
+			return lvl
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return levels.root
+}
+
+// enabled reports whether the logger named name logs entries at lvl
+func enabled(name string, lvl log.Level) bool {
+	levels.RLock()
+	defer levels.RUnlock()
+	return lvl <= effectiveLevel(name)
+}
+
+// levelState is the JSON document served by LevelHandler
+type levelState struct {
+	Level   string            `json:"level"`
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// levelChange is the JSON body accepted by LevelHandler to change a level
+type levelChange struct {
+	// Logger is the named logger to change; empty for the default logger
+	Logger string `json:"logger,omitempty"`
+	// Level is the new level; empty removes the override of a named logger
+	Level string `json:"level"`
+}
+
+// LevelHandler returns an HTTP handler to read and change log levels at runtime.
+// GET returns the levels as JSON, e.g. {"level":"info","loggers":{"codegen":"debug"}}.
+// PUT or POST a JSON body such as {"level":"debug"} or {"logger":"codegen","level":"debug"}
+// to change a level; the response holds the new levels.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			var change levelChange
+			if err := json.NewDecoder(req.Body).Decode(&change); err != nil {
+				http.Error(rw, fmt.Sprintf("invalid level change: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := SetNamedLevel(change.Logger, change.Level); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			Infof("log level of %q set to %q", levelLoggerName(change.Logger), change.Level)
+		default:
+			rw.Header().Set("Allow", "GET, HEAD, PUT, POST")
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(levelState{Level: Level(), Loggers: NamedLevels()})
+	})
+}
+
+// levelLoggerName returns the name of a logger for messages
+func levelLoggerName(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// withLevels restores the default levels once the test is done
+func withLevels(t *testing.T, spec string) {
+	t.Helper()
+	if err := SetLevels(spec); err != nil {
+		t.Fatalf("SetLevels(%q) failed: %v", spec, err)
+	}
+	t.Cleanup(func() { SetLevels(LogLevelDefault) })
+}
+
+func TestSetLevels(t *testing.T) {
+	withLevels(t, "warn, codegen=debug ,codegen.template=error")
+
+	if got := Level(); got != LogLevelWarn {
+		t.Fatalf("Expected to be %q received %q", LogLevelWarn, got)
+	}
+	tests := map[string]string{
+		"codegen":          LogLevelDebug,
+		"codegen.template": LogLevelError,
+		"codegen.module":   LogLevelDebug,
+		"http":             LogLevelWarn,
+	}
+	for name, want := range tests {
+		if got := NamedLevel(name); got != want {
+			t.Fatalf("Expected level of %q to be %q received %q", name, want, got)
+		}
+	}
+	// logrus must let the entries of the most verbose logger through
+	if got := log.GetLevel(); got != log.DebugLevel {
+		t.Fatalf("Expected to be %v received %v", log.DebugLevel, got)
+	}
+
+	if err := SetLevels("info,codegen=loud"); err == nil {
+		t.Fatalf("Expected an error for an invalid level")
+	}
+	if err := SetLevel("panic"); err == nil {
+		t.Fatalf("Expected an error for the panic level")
+	}
+}
+
+func TestNamedLoggerLevel(t *testing.T) {
+	withLevels(t, "info,codegen=debug")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	Debug("root debug")
+	Named("codegen").Debug("codegen debug")
+	Named("http").Debug("http debug")
+
+	out := buf.String()
+	if strings.Contains(out, "root debug") || strings.Contains(out, "http debug") {
+		t.Fatalf("Expected debug entries of the default logger to be dropped, received %q", out)
+	}
+	if !strings.Contains(out, "codegen debug") || !strings.Contains(out, "logger=codegen") {
+		t.Fatalf("Expected the debug entry of the codegen logger, received %q", out)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	withLevels(t, LogLevelDefault)
+	handler := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"logger":"codegen","level":"debug"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected to be %d received %d: %s", http.StatusOK, rec.Code, rec.Body)
+	}
+	if got := NamedLevel("codegen"); got != LogLevelDebug {
+		t.Fatalf("Expected to be %q received %q", LogLevelDebug, got)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if want := `{"level":"info","loggers":{"codegen":"debug"}}`; strings.TrimSpace(rec.Body.String()) != want {
+		t.Fatalf("Expected to be %s received %s", want, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"level":"loud"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected to be %d received %d", http.StatusBadRequest, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected to be %d received %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
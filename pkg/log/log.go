@@ -3,6 +3,15 @@
 // 1. json
 // 2. text
 // Text format is the default
+//
+// The log level is set with the environment variable `LOG_LEVEL` (trace, debug, info, warn,
+// error or fatal; info is the default), optionally followed by levels of named loggers:
+// LOG_LEVEL=info,codegen=debug. Levels can be changed at runtime with SetLevel and
+// SetNamedLevel, or over HTTP with LevelHandler.
+//
+// Entries carry structured fields: pass key-value pairs to the logging functions
+// (log.Info("served", "status", 200)) or attach them to a Logger with With and WithError.
+// Loggers travel with requests through IntoContext and FromContext.
 package log
 
 import (
@@ -31,43 +40,80 @@ func init() {
 	}
 }
 
-// Log at Info level
-func Info(msg string) {
-	log.Info(msg)
+// std is the default logger, used by the package level functions
+var std = Named("")
 
+// Default returns the default logger, used by the package level functions
+func Default() *Logger {
+	return std
+}
+
+// With returns a logger adding the key-value pairs keyvals to every entry. See Logger.With
+func With(keyvals ...interface{}) *Logger {
+	return std.With(keyvals...)
+}
+
+// WithError returns a logger adding err to every entry, under the "error" key
+func WithError(err error) *Logger {
+	return std.WithError(err)
+}
+
+// Log at Info level, with the key-value pairs keyvals attached to the entry
+// (e.g. log.Info("request served", "path", path, "status", status))
+func Info(msg string, keyvals ...interface{}) {
+	std.Info(msg, keyvals...)
 }
 
 // Log at WARN level.  Logs the err message first followed by the whole error
-func Warn(msg string, err error) {
-	log.Warn(msg)
-	log.Warn(err)
+func Warn(msg string, err error, keyvals ...interface{}) {
+	std.Warn(msg, err, keyvals...)
+}
+
+// Log at Error level, with the key-value pairs keyvals attached to the entry
+func Error(msg string, keyvals ...interface{}) {
+	std.Error(msg, keyvals...)
 }
 
-// Log at Debug level
-func Debug(msg string) {
-	log.Debug(msg)
+// Log at Debug level, with the key-value pairs keyvals attached to the entry
+func Debug(msg string, keyvals ...interface{}) {
+	std.Debug(msg, keyvals...)
 }
 
 // Log at Fatal level.  Sequence is:
 // 1. Log the actual error
 // 2. Exit with code 1
 func Fatal(err error) {
-	log.Error(err)
-	os.Exit(1)
+	std.Fatal(err)
+}
+
+// Fielder is implemented by values logged as structured fields, such as the
+// configs wrapped by env.Redacted in pkg/v2/os/env.
+type Fielder interface {
+	Fields() map[string]interface{}
+}
+
+// Log at Info level, with the fields of f attached to the entry
+func InfoFields(msg string, f Fielder) {
+	std.WithFields(f).Info(msg)
+}
+
+// Log at Debug level, with the fields of f attached to the entry
+func DebugFields(msg string, f Fielder) {
+	std.WithFields(f).Debug(msg)
 }
 
 func Debugf(format string, args ...interface{}) {
-	log.Debugf(format+"\n", args...)
+	std.Debugf(format, args...)
 }
 
 func Infof(format string, args ...interface{}) {
-	log.Infof(format+"\n", args...)
+	std.Infof(format, args...)
 }
 
 func Warnf(format string, args ...interface{}) {
-	log.Warnf(format+"\n", args...)
+	std.Warnf(format, args...)
 }
 
 func Errorf(format string, args ...interface{}) {
-	log.Errorf(format+"\n", args...)
+	std.Errorf(format, args...)
 }
@@ -0,0 +1,172 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Logger logs entries tagged with its name, at the level configured for that name
+// with SetNamedLevel or `LOG_LEVEL` (e.g. LOG_LEVEL=info,codegen=debug).
+// Fields attached with With and WithError are added to every entry; a Logger is
+// immutable, so it can be shared between goroutines.
+type Logger struct {
+	name   string
+	fields log.Fields
+}
+
+// Field key of the logger name, and of the error attached with WithError
+const (
+	FieldLogger = "logger"
+	FieldError  = "error"
+)
+
+// fieldMissing is the value of a key passed to With without a value
+const fieldMissing = "(MISSING)"
+
+// Named returns the logger named name, typically the name of a package (e.g. "codegen").
+// Dotted names (e.g. "codegen.template") inherit the level of their enclosing name.
+func Named(name string) *Logger {
+	return &Logger{name: name}
+}
+
+// Name returns the name of the logger
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// Named returns a logger named after l and name (e.g. "codegen.template"), with the fields of l
+func (l *Logger) Named(name string) *Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	return &Logger{name: name, fields: l.fields}
+}
+
+// With returns a logger adding the key-value pairs keyvals to every entry
+// (e.g. With("request_id", id, "user_id", user)). Keys are converted to strings.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	if len(keyvals) == 0 {
+		return l
+	}
+	fields := make(log.Fields, len(l.fields)+len(keyvals)/2)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	addFields(fields, keyvals)
+	return &Logger{name: l.name, fields: fields}
+}
+
+// WithError returns a logger adding err to every entry, under the "error" key
+func (l *Logger) WithError(err error) *Logger {
+	return l.With(FieldError, err)
+}
+
+// WithFields returns a logger adding the fields of f to every entry
+func (l *Logger) WithFields(f Fielder) *Logger {
+	var keyvals []interface{}
+	for k, v := range f.Fields() {
+		keyvals = append(keyvals, k, v)
+	}
+	return l.With(keyvals...)
+}
+
+// addFields adds the key-value pairs keyvals to fields
+func addFields(fields log.Fields, keyvals []interface{}) {
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 < len(keyvals) {
+			fields[key] = keyvals[i+1]
+		} else {
+			fields[key] = fieldMissing
+		}
+	}
+}
+
+// entry returns the logrus entry for the logger's entries, with keyvals added to its fields
+func (l *Logger) entry(keyvals []interface{}) *log.Entry {
+	fields := make(log.Fields, len(l.fields)+len(keyvals)/2+1)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	addFields(fields, keyvals)
+	if l.name != "" {
+		fields[FieldLogger] = l.name
+	}
+	return log.WithFields(fields)
+}
+
+// logw logs msg at lvl, with the key-value pairs keyvals, if the logger is enabled for lvl
+func (l *Logger) logw(lvl log.Level, msg string, keyvals []interface{}) {
+	if enabled(l.name, lvl) {
+		l.entry(keyvals).Log(lvl, msg)
+	}
+}
+
+// logf logs at lvl if the logger is enabled for it
+func (l *Logger) logf(lvl log.Level, format string, args ...interface{}) {
+	if enabled(l.name, lvl) {
+		l.entry(nil).Logf(lvl, format, args...)
+	}
+}
+
+// Log at Info level, with the key-value pairs keyvals attached to the entry
+func (l *Logger) Info(msg string, keyvals ...interface{}) {
+	l.logw(log.InfoLevel, msg, keyvals)
+}
+
+// Log at WARN level.  Logs the err message first followed by the whole error
+func (l *Logger) Warn(msg string, err error, keyvals ...interface{}) {
+	l.logw(log.WarnLevel, msg, keyvals)
+	l.logw(log.WarnLevel, fmt.Sprint(err), keyvals)
+}
+
+// Log at Error level, with the key-value pairs keyvals attached to the entry
+func (l *Logger) Error(msg string, keyvals ...interface{}) {
+	l.logw(log.ErrorLevel, msg, keyvals)
+}
+
+// Log at Debug level, with the key-value pairs keyvals attached to the entry
+func (l *Logger) Debug(msg string, keyvals ...interface{}) {
+	l.logw(log.DebugLevel, msg, keyvals)
+}
+
+// Log at Fatal level and exit with code 1. The entry is logged whatever the level of the logger
+func (l *Logger) Fatal(err error) {
+	l.entry(nil).Log(log.FatalLevel, err)
+	os.Exit(1)
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(log.DebugLevel, format+"\n", args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(log.InfoLevel, format+"\n", args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(log.WarnLevel, format+"\n", args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(log.ErrorLevel, format+"\n", args...)
+}
+
+// contextKey is the context key of the logger stored by IntoContext
+type contextKey struct{}
+
+// IntoContext returns a copy of ctx carrying l, retrieved with FromContext
+func IntoContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by IntoContext, else the default logger
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return std
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// captureJSON returns a buffer receiving the JSON entries logged during the test
+func captureJSON(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	withLevels(t, LogLevelDefault)
+	var buf bytes.Buffer
+	formatter := log.StandardLogger().Formatter
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.JSONFormatter{})
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFormatter(formatter)
+	})
+	return &buf
+}
+
+// lastEntry decodes the last JSON entry of buf
+func lastEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var entry map[string]interface{}
+	if err := json.Unmarshal(lines[len(lines)-1], &entry); err != nil {
+		t.Fatalf("Expected a JSON entry received %q: %v", buf, err)
+	}
+	return entry
+}
+
+func TestLoggerWith(t *testing.T) {
+	buf := captureJSON(t)
+
+	logger := Named("http").With("request_id", "r-1").WithError(errors.New("boom"))
+	logger.Info("served", "status", 200, "dangling")
+
+	entry := lastEntry(t, buf)
+	want := map[string]interface{}{
+		"msg":        "served",
+		"level":      "info",
+		"logger":     "http",
+		"request_id": "r-1",
+		"error":      "boom",
+		"status":     float64(200),
+		"dangling":   fieldMissing,
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Fatalf("Expected %s to be %v received %v", k, v, entry[k])
+		}
+	}
+
+	// With must not change the fields of the parent logger
+	Named("http").Info("plain")
+	if entry := lastEntry(t, buf); entry["request_id"] != nil {
+		t.Fatalf("Expected no request_id received %v", entry["request_id"])
+	}
+}
+
+func TestLoggerContext(t *testing.T) {
+	buf := captureJSON(t)
+
+	if FromContext(context.Background()) != Default() {
+		t.Fatalf("Expected the default logger without a logger in the context")
+	}
+	ctx := IntoContext(context.Background(), With("user_id", "u-1").Named("codegen"))
+	FromContext(ctx).Debug("dropped")
+	FromContext(ctx).Warn("careful", errors.New("oops"))
+
+	entry := lastEntry(t, buf)
+	if entry["msg"] != "oops" || entry["user_id"] != "u-1" || entry["logger"] != "codegen" {
+		t.Fatalf("Expected the warning of the context logger received %v", entry)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("dropped")) {
+		t.Fatalf("Expected debug entries to be dropped received %q", buf)
+	}
+}
+
+func TestFatalIgnoresLevel(t *testing.T) {
+	if os.Getenv("LOG_TEST_FATAL") == "1" {
+		Fatal(errors.New("fatal-error"))
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalIgnoresLevel$")
+	cmd.Env = append(os.Environ(), "LOG_TEST_FATAL=1", "LOG_LEVEL=fatal")
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("Expected exit code 1 received %v", err)
+	}
+	if !bytes.Contains(out, []byte("level=fatal")) || !bytes.Contains(out, []byte("fatal-error")) {
+		t.Fatalf("Expected the fatal entry to be logged received %q", out)
+	}
+}
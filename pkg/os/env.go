@@ -8,6 +8,9 @@ import (
 	"github.com/finiteloopme/goutils/pkg/log"
 )
 
+// Exits if key is not set.
+//
+// Deprecated: use env.String(key, env.Required()) in pkg/v2/os/env, which returns an error instead.
 func ReadEnvVar(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -16,6 +19,7 @@ func ReadEnvVar(key string) string {
 	return value
 }
 
+// Deprecated: use env.String(key) in pkg/v2/os/env.
 func ReadEnvVarOptional(key string) string {
 	return os.Getenv(key)
 }
@@ -1,15 +1,18 @@
 // Process configuration for the app
 //
 // Order of priority:
-// 1. Environment config: see github.com/kelseyhightower/envconfig
+// 1. Environment config: github.com/kelseyhightower/envconfig conventions, see NewEnvconfigLoader in pkg/v2/os/env
 // 2. Config file: optional. Default: ./config.yaml
+//
+// An active profile (APP_PROFILE, or ProcessProfile) layers overlays on top: see profile.go
 package env
 
 import (
+	"context"
 	"os"
 
 	"github.com/finiteloopme/goutils/pkg/log"
-	"github.com/kelseyhightower/envconfig"
+	v2env "github.com/finiteloopme/goutils/pkg/v2/os/env"
 	"gopkg.in/yaml.v2"
 )
 
@@ -23,7 +26,13 @@ func ProcessFileconfig(filename string) (*os.File, error) {
 }
 
 func ProcessEnvconfig(prefixEnvVar string, structRecord interface{}) error {
-	err := envconfig.Process(prefixEnvVar, structRecord)
+	return processEnvconfig(prefixEnvVar, structRecord, "")
+}
+
+// Processes environment variables and the `default` tags, or `default.<profile>` tags if set
+func processEnvconfig(prefixEnvVar string, structRecord interface{}, profile string) error {
+	loader := v2env.NewEnvconfigLoader(v2env.WithProfile(profile), v2env.WithProfileEnv(""))
+	err := loader.Load(context.Background(), prefixEnvVar, structRecord)
 	if err != nil {
 		log.Warn("Error reading environment variables. ", err)
 		return err
@@ -31,17 +40,24 @@ func ProcessEnvconfig(prefixEnvVar string, structRecord interface{}) error {
 	return nil
 }
 
+// Process the configuration, with the active profile read from APP_PROFILE
 func Process(prefixEnvVar string, structRecord interface{}, _configFilename ...string) error {
+	return ProcessProfile(os.Getenv(ProfileEnvVar), prefixEnvVar, structRecord, _configFilename...)
+}
+
+// Process the configuration with the given profile (e.g. "prod").  No overlays if the profile is empty
+func ProcessProfile(profile string, prefixEnvVar string, structRecord interface{}, _configFilename ...string) error {
 	var file *os.File
 	var err error
+	configFilename := "./config.yaml"
 	if len(_configFilename) > 0 && _configFilename[0] != "" {
-		file, err = ProcessFileconfig(_configFilename[0])
+		configFilename = _configFilename[0]
+		file, err = ProcessFileconfig(configFilename)
 		if err != nil {
 			log.Warn("error reading config file. ", err)
 			return err
 		}
 	} else {
-		configFilename := "./config.yaml"
 		file, err = ProcessFileconfig(configFilename)
 		if err != nil {
 			log.Warn("error reading default config file. ", err)
@@ -51,11 +67,16 @@ func Process(prefixEnvVar string, structRecord interface{}, _configFilename ...s
 	}
 
 	if err == nil {
+		defer file.Close()
 		err = yaml.NewDecoder(file).Decode(structRecord)
 		if err != nil {
 			log.Warn("Error decoding config file to struct. ", err)
 			// check if environment variables are configured
 		}
 	}
-	return ProcessEnvconfig(prefixEnvVar, structRecord)
+	if profile != "" {
+		processProfileFileconfig(configFilename, profile, structRecord)
+		processProfileDotenv(profile)
+	}
+	return processEnvconfig(prefixEnvVar, structRecord, profile)
 }
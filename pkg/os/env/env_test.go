@@ -42,3 +42,43 @@ func TestProcess(t *testing.T) {
 		assert.NoError(t1, err, "Unexpected error. ", err)
 	})
 }
+
+func TestProcessProfile(t *testing.T) {
+	type recordType struct {
+		Name     string `yaml:"name"`
+		Replicas int    `yaml:"replicas" default:"1" default.prod:"3"`
+		Region   string `yaml:"region" envconfig:"PROFILE_TEST_REGION"`
+	}
+
+	t.Run("Profile overlays", func(t1 *testing.T) {
+		dir := t1.TempDir()
+		configFilename := dir + "/config.yaml"
+		tearDownBase := setupProcessTest(configFilename, &recordType{Name: "base"})
+		defer tearDownBase()
+		tearDownOverlay := setupProcessTest(dir+"/config.prod.yaml", map[string]string{"name": "production"})
+		defer tearDownOverlay()
+
+		var actual recordType
+		err := env.ProcessProfile("prod", "", &actual, configFilename)
+		assert.NoError(t1, err, "Unexpected error. ", err)
+		assert.Equal(t1, "production", actual.Name, "Expected name from the profile config file")
+		assert.Equal(t1, 3, actual.Replicas, "Expected replicas from the profile default")
+	})
+
+	t.Run("Environment overrides profile defaults", func(t1 *testing.T) {
+		t1.Setenv("REPLICAS", "5")
+		t1.Setenv(env.ProfileEnvVar, "prod")
+		var actual recordType
+		err := env.Process("", &actual, "")
+		assert.NoError(t1, err, "Unexpected error. ", err)
+		assert.Equal(t1, 5, actual.Replicas, "Expected replicas from the environment")
+	})
+
+	t.Run("No profile", func(t1 *testing.T) {
+		t1.Setenv(env.ProfileEnvVar, "")
+		var actual recordType
+		err := env.Process("", &actual, "")
+		assert.NoError(t1, err, "Unexpected error. ", err)
+		assert.Equal(t1, 1, actual.Replicas, "Expected replicas from the base default")
+	})
+}
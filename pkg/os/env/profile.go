@@ -0,0 +1,52 @@
+// Profile overlays for the app configuration
+//
+// When a profile is active (e.g. APP_PROFILE=prod), Process layers on top of the base sources:
+// 1. Config file overlay: config.<profile>.yaml next to the config file
+// 2. Dotenv file: ./.env.<profile>, without overriding variables already set
+// 3. Profile defaults: `default.<profile>` tags, used instead of `default` tags by the v2 env loader
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/finiteloopme/goutils/pkg/log"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+)
+
+// Environment variable naming the active profile
+const ProfileEnvVar = "APP_PROFILE"
+
+// Returns the profile overlay of a config file: config.yaml becomes config.prod.yaml
+func profileConfigFilename(filename string, profile string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "." + profile + ext
+}
+
+// Decodes the profile overlay of the config file on top of structRecord, if it exists
+func processProfileFileconfig(filename string, profile string, structRecord interface{}) {
+	overlay := profileConfigFilename(filename, profile)
+	file, err := os.Open(overlay)
+	if err != nil {
+		// Profile overlays are optional
+		return
+	}
+	defer file.Close()
+	if err := yaml.NewDecoder(file).Decode(structRecord); err != nil {
+		log.Warn("Error decoding profile config file to struct. ", err)
+	}
+}
+
+// Loads ./.env.<profile> into the environment, without overriding variables already set
+func processProfileDotenv(profile string) {
+	filename := ".env." + profile
+	if _, err := os.Stat(filename); err != nil {
+		// Profile overlays are optional
+		return
+	}
+	if err := godotenv.Load(filename); err != nil {
+		log.Warn("error reading profile dotenv file. ", err)
+	}
+}
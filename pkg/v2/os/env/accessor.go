@@ -0,0 +1,132 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// VarOption configures a typed accessor such as String or Int.
+type VarOption func(*varOptions)
+
+type varOptions struct {
+	defaultValue *string
+	required     bool
+	secret       string
+	separator    string
+	prefix       string
+	loader       *Loader
+}
+
+// Default sets the raw value used when the variable is not set, as a `default` tag
+// (e.g., Default("8080") for Int, Default("5s") for Duration).
+func Default(value string) VarOption {
+	return func(o *varOptions) { o.defaultValue = &value }
+}
+
+// Required fails the accessor with ErrRequired if the variable has no value.
+func Required() VarOption {
+	return func(o *varOptions) { o.required = true }
+}
+
+// Secret reads the value from the named secret, as a `secret` tag, when it is set.
+func Secret(name string) VarOption {
+	return func(o *varOptions) { o.secret = name }
+}
+
+// Separator splits the elements of Slice values on sep instead of ",".
+func Separator(sep string) VarOption {
+	return func(o *varOptions) { o.separator = sep }
+}
+
+// Prefix prepends prefix to the variable name, as the prefix passed to ProcessConfig.
+func Prefix(prefix string) VarOption {
+	return func(o *varOptions) { o.prefix = prefix }
+}
+
+// FromLoader reads the variable with l instead of the loader backing ProcessConfig.
+func FromLoader(l *Loader) VarOption {
+	return func(o *varOptions) { o.loader = l }
+}
+
+// Get reads the environment variable key into a value of type T, with the same
+// sources, conversions and errors as a struct field tagged `env:"key"` loaded by
+// ProcessConfig: .env files, defaults, secrets and validation all apply, but
+// command-line flags are not parsed. Without a value, it returns the zero value of T.
+//
+// Example:
+//
+//	port, err := env.Get[int]("PORT", env.Default("8080"), env.Prefix("APP_"))
+func Get[T any](key string, opts ...VarOption) (T, error) {
+	o := varOptions{loader: defaultLoader}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	tags := []string{fmt.Sprintf("%s:%q", TagEnv, key)}
+	if o.defaultValue != nil {
+		tags = append(tags, fmt.Sprintf("%s:%q", TagDefault, *o.defaultValue))
+	}
+	if o.required {
+		tags = append(tags, fmt.Sprintf("%s:%q", TagRequired, "true"))
+	}
+	if o.secret != "" {
+		tags = append(tags, fmt.Sprintf("%s:%q", TagSecret, o.secret))
+	}
+	if o.separator != "" {
+		tags = append(tags, fmt.Sprintf("%s:%q", TagSeparator, o.separator))
+	}
+
+	// Load a struct holding a single field tagged like a spec field.
+	holderType := reflect.StructOf([]reflect.StructField{{
+		Name: accessorFieldName(key),
+		Type: reflect.TypeOf((*T)(nil)).Elem(),
+		Tag:  reflect.StructTag(strings.Join(tags, " ")),
+	}})
+	holder := reflect.New(holderType)
+	_, err := o.loader.load(context.Background(), o.prefix, holder.Interface(), false)
+	return holder.Elem().Field(0).Interface().(T), err
+}
+
+// String reads the environment variable key as a string. See Get.
+func String(key string, opts ...VarOption) (string, error) {
+	return Get[string](key, opts...)
+}
+
+// Int reads the environment variable key as an int. See Get.
+func Int(key string, opts ...VarOption) (int, error) {
+	return Get[int](key, opts...)
+}
+
+// Duration reads the environment variable key as a time.Duration (e.g., "5s"). See Get.
+func Duration(key string, opts ...VarOption) (time.Duration, error) {
+	return Get[time.Duration](key, opts...)
+}
+
+// Bool reads the environment variable key as a bool. See Get.
+func Bool(key string, opts ...VarOption) (bool, error) {
+	return Get[bool](key, opts...)
+}
+
+// Slice reads the environment variable key as a comma separated list of strings. See Get.
+func Slice(key string, opts ...VarOption) ([]string, error) {
+	return Get[[]string](key, opts...)
+}
+
+// accessorFieldName returns the name of the field holding key, which errors refer to:
+// key itself if it is an exported Go identifier (e.g., "PORT"), else "Value".
+func accessorFieldName(key string) string {
+	for i, r := range key {
+		switch {
+		case r == '_' && i > 0, unicode.IsDigit(r) && i > 0, unicode.IsLetter(r) && (i > 0 || unicode.IsUpper(r)):
+		default:
+			return "Value"
+		}
+	}
+	if key == "" {
+		return "Value"
+	}
+	return key
+}
@@ -0,0 +1,75 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAccessors tests the typed accessors, their defaults and their prefix.
+func TestAccessors(t *testing.T) {
+	l := newTestLoader(nil, map[string]string{
+		"APP_HOST":    "example.com",
+		"APP_PORT":    "9090",
+		"APP_TIMEOUT": "3s",
+		"APP_DEBUG":   "true",
+		"APP_TAGS":    "a;b",
+	})
+	opts := []VarOption{FromLoader(l), Prefix("APP_")}
+
+	if host, err := String("HOST", opts...); err != nil || host != "example.com" {
+		t.Errorf("Expected example.com, got %q: %v", host, err)
+	}
+	if port, err := Int("PORT", opts...); err != nil || port != 9090 {
+		t.Errorf("Expected 9090, got %d: %v", port, err)
+	}
+	if timeout, err := Duration("TIMEOUT", opts...); err != nil || timeout != 3*time.Second {
+		t.Errorf("Expected 3s, got %v: %v", timeout, err)
+	}
+	if debug, err := Bool("DEBUG", opts...); err != nil || !debug {
+		t.Errorf("Expected true, got %v: %v", debug, err)
+	}
+	if tags, err := Slice("TAGS", append(opts, Separator(";"))...); err != nil || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v: %v", tags, err)
+	}
+	if retries, err := Int("RETRIES", append(opts, Default("3"))...); err != nil || retries != 3 {
+		t.Errorf("Expected the default 3, got %d: %v", retries, err)
+	}
+	if unset, err := String("UNSET", opts...); err != nil || unset != "" {
+		t.Errorf("Expected an empty value, got %q: %v", unset, err)
+	}
+}
+
+// TestAccessorErrors tests that accessors return errors rather than exiting.
+func TestAccessorErrors(t *testing.T) {
+	l := newTestLoader(nil, map[string]string{"PORT": "http"})
+
+	_, err := String("API_KEY", FromLoader(l), Required())
+	if !errors.Is(err, ErrRequired) || !strings.Contains(err.Error(), `"API_KEY"`) {
+		t.Errorf("Expected a required error for API_KEY, got %v", err)
+	}
+	if _, err := Int("PORT", FromLoader(l)); !errors.Is(err, ErrParse) {
+		t.Errorf("Expected a parse error, got %v", err)
+	}
+}
+
+// TestAccessorSecret tests reading a value from a secret.
+func TestAccessorSecret(t *testing.T) {
+	secrets := NewMemorySecretProvider(map[string]string{"api-key": "s3cret"})
+	l := newTestLoader(nil, nil, WithSecretProvider(secrets))
+	if key, err := String("API_KEY", FromLoader(l), Secret("api-key"), Required()); err != nil || key != "s3cret" {
+		t.Errorf("Expected the secret value, got %q: %v", key, err)
+	}
+}
+
+// TestAccessorFieldName tests the names of the fields holding accessor values.
+func TestAccessorFieldName(t *testing.T) {
+	tests := map[string]string{"PORT": "PORT", "DB_HOST2": "DB_HOST2", "port": "Value", "DB.HOST": "Value", "_X": "Value", "": "Value"}
+	for key, want := range tests {
+		if got := accessorFieldName(key); got != want {
+			t.Errorf("Expected %q for %q, got %q", want, key, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// defaultSeparator splits the elements of slice and map values.
+	defaultSeparator = ","
+	// defaultKVSeparator splits the key from the value of a map entry.
+	defaultKVSeparator = ":"
+)
+
+// valueOptions controls how a raw string value is converted into a field value.
+type valueOptions struct {
+	// separator splits slice elements and map entries.
+	separator string
+	// kvSeparator splits map entries into key and value.
+	kvSeparator string
+}
+
+// valueOptionsFromTag builds the conversion options for a field from its struct tags.
+func valueOptionsFromTag(tag reflect.StructTag) valueOptions {
+	opts := valueOptions{separator: defaultSeparator, kvSeparator: defaultKVSeparator}
+	if sep, ok := tag.Lookup(TagSeparator); ok && sep != "" {
+		opts.separator = sep
+	}
+	if sep, ok := tag.Lookup(TagKVSeparator); ok && sep != "" {
+		opts.kvSeparator = sep
+	}
+	return opts
+}
+
+// setSliceValue splits value on the separator and sets each element on the slice field.
+// An empty value results in a nil slice. []byte fields receive the raw value.
+func setSliceValue(field reflect.Value, value string, opts valueOptions) error {
+	fieldType := field.Type()
+	if fieldType.Elem().Kind() == reflect.Uint8 {
+		field.SetBytes([]byte(value))
+		return nil
+	}
+	if value == "" {
+		field.Set(reflect.Zero(fieldType))
+		return nil
+	}
+
+	parts := strings.Split(value, opts.separator)
+	slice := reflect.MakeSlice(fieldType, len(parts), len(parts))
+	for i, part := range parts {
+		if err := setFieldValue(slice.Index(i), strings.TrimSpace(part), opts); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
+// setMapValue splits value into entries on the separator, and each entry into a
+// key and value on the key/value separator (e.g. "env:prod,team:core").
+func setMapValue(field reflect.Value, value string, opts valueOptions) error {
+	fieldType := field.Type()
+	if value == "" {
+		field.Set(reflect.Zero(fieldType))
+		return nil
+	}
+
+	m := reflect.MakeMap(fieldType)
+	for _, entry := range strings.Split(value, opts.separator) {
+		kv := strings.SplitN(entry, opts.kvSeparator, 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid map entry %q: expected key%svalue", entry, opts.kvSeparator)
+		}
+		key := reflect.New(fieldType.Key()).Elem()
+		if err := setFieldValue(key, strings.TrimSpace(kv[0]), opts); err != nil {
+			return fmt.Errorf("map key %q: %w", kv[0], err)
+		}
+		elem := reflect.New(fieldType.Elem()).Elem()
+		if err := setFieldValue(elem, strings.TrimSpace(kv[1]), opts); err != nil {
+			return fmt.Errorf("map value for key %q: %w", kv[0], err)
+		}
+		m.SetMapIndex(key, elem)
+	}
+	field.Set(m)
+	return nil
+}
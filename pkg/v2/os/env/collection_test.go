@@ -0,0 +1,80 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestSetFieldValueCollections tests parsing of slice and map values.
+func TestSetFieldValueCollections(t *testing.T) {
+	type config struct {
+		Origins   []string `separator:";"`
+		Ports     []int
+		Timeouts  []time.Duration
+		Labels    map[string]string `kvseparator:"="`
+		Weights   map[string]int
+		Raw       []byte
+		Optionals []*string
+	}
+	var cfg config
+	v := reflect.ValueOf(&cfg).Elem()
+	typ := v.Type()
+
+	values := map[string]string{
+		"Origins":   "a.com; b.com",
+		"Ports":     "80,443",
+		"Timeouts":  "1s,2m",
+		"Labels":    "env=prod,team=core",
+		"Weights":   "a:1,b:2",
+		"Raw":       "a,b",
+		"Optionals": "x",
+	}
+	for name, value := range values {
+		sf, _ := typ.FieldByName(name)
+		if err := setFieldValue(v.FieldByName(name), value, valueOptionsFromTag(sf.Tag)); err != nil {
+			t.Fatalf("setFieldValue(%s, %q) failed: %v", name, value, err)
+		}
+	}
+
+	if !reflect.DeepEqual(cfg.Origins, []string{"a.com", "b.com"}) {
+		t.Errorf("Expected Origins [a.com b.com], got %v", cfg.Origins)
+	}
+	if !reflect.DeepEqual(cfg.Ports, []int{80, 443}) {
+		t.Errorf("Expected Ports [80 443], got %v", cfg.Ports)
+	}
+	if !reflect.DeepEqual(cfg.Timeouts, []time.Duration{time.Second, 2 * time.Minute}) {
+		t.Errorf("Expected Timeouts [1s 2m0s], got %v", cfg.Timeouts)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]string{"env": "prod", "team": "core"}) {
+		t.Errorf("Expected Labels map[env:prod team:core], got %v", cfg.Labels)
+	}
+	if !reflect.DeepEqual(cfg.Weights, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("Expected Weights map[a:1 b:2], got %v", cfg.Weights)
+	}
+	if string(cfg.Raw) != "a,b" {
+		t.Errorf("Expected Raw 'a,b', got %q", cfg.Raw)
+	}
+	if len(cfg.Optionals) != 1 || *cfg.Optionals[0] != "x" {
+		t.Errorf("Expected Optionals [x], got %v", cfg.Optionals)
+	}
+}
+
+// TestSetFieldValueCollectionErrors tests that malformed collection values are rejected.
+func TestSetFieldValueCollectionErrors(t *testing.T) {
+	var ports []int
+	if err := setFieldValue(reflect.ValueOf(&ports).Elem(), "80,http", valueOptionsFromTag("")); err == nil {
+		t.Errorf("Expected error for non-integer slice element, got nil")
+	}
+
+	var labels map[string]string
+	if err := setFieldValue(reflect.ValueOf(&labels).Elem(), "env", valueOptionsFromTag("")); err == nil {
+		t.Errorf("Expected error for map entry without separator, got nil")
+	}
+
+	// An empty value leaves the slice nil so `required` still applies.
+	origins := []string{"stale"}
+	if err := setFieldValue(reflect.ValueOf(&origins).Elem(), "", valueOptionsFromTag("")); err != nil || origins != nil {
+		t.Errorf("Expected nil slice for empty value, got %v (err: %v)", origins, err)
+	}
+}
@@ -0,0 +1,113 @@
+package env
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+// DecoderFunc converts a raw string value into a value of the type it is registered for.
+// The returned value must be assignable (or convertible) to that type.
+type DecoderFunc func(value string) (interface{}, error)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+
+	// decodersMu guards decoders.
+	decodersMu sync.RWMutex
+	// decoders holds the user-supplied decoder functions keyed by target type.
+	// url.URL is registered by default as it implements neither encoding.TextUnmarshaler nor flag.Value.
+	decoders = map[reflect.Type]DecoderFunc{
+		reflect.TypeOf(url.URL{}): func(value string) (interface{}, error) {
+			u, err := url.Parse(value)
+			if err != nil {
+				return nil, err
+			}
+			return *u, nil
+		},
+	}
+)
+
+// RegisterDecoder registers fn as the decoder for fields of type typ, taking
+// precedence over encoding.TextUnmarshaler, flag.Value and the built-in conversions.
+// Registering a decoder for T also covers *T, []T and map values of T.
+// Passing a nil fn removes the decoder for typ.
+//
+// Example:
+//
+//	env.RegisterDecoder(reflect.TypeOf(Level(0)), func(s string) (interface{}, error) {
+//		return ParseLevel(s)
+//	})
+func RegisterDecoder(typ reflect.Type, fn DecoderFunc) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if fn == nil {
+		delete(decoders, typ)
+		return
+	}
+	decoders[typ] = fn
+}
+
+// lookupDecoder returns the registered decoder for typ, if any.
+func lookupDecoder(typ reflect.Type) (DecoderFunc, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	fn, ok := decoders[typ]
+	return fn, ok
+}
+
+// hasCustomDecoder reports whether values of typ (or of the type typ points to)
+// are decoded by a registered decoder, encoding.TextUnmarshaler or flag.Value
+// instead of the built-in conversions.
+func hasCustomDecoder(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		if _, ok := lookupDecoder(typ); ok {
+			return true
+		}
+		typ = typ.Elem()
+	}
+	if _, ok := lookupDecoder(typ); ok {
+		return true
+	}
+	ptr := reflect.PointerTo(typ)
+	return ptr.Implements(textUnmarshalerType) || ptr.Implements(flagValueType)
+}
+
+// decodeCustom sets field from value using a registered decoder, encoding.TextUnmarshaler
+// or flag.Value, in that order. It reports whether one of them handled the field.
+func decodeCustom(field reflect.Value, value string) (bool, error) {
+	fieldType := field.Type()
+	if fn, ok := lookupDecoder(fieldType); ok {
+		decoded, err := fn(value)
+		if err != nil {
+			return true, err
+		}
+		v := reflect.ValueOf(decoded)
+		switch {
+		case !v.IsValid():
+			field.Set(reflect.Zero(fieldType))
+		case v.Type().AssignableTo(fieldType):
+			field.Set(v)
+		case v.Type().ConvertibleTo(fieldType):
+			field.Set(v.Convert(fieldType))
+		default:
+			return true, fmt.Errorf("decoder for %s returned incompatible type %s", fieldType, v.Type())
+		}
+		return true, nil
+	}
+
+	if !field.CanAddr() {
+		return false, nil
+	}
+	switch target := field.Addr().Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return true, target.UnmarshalText([]byte(value))
+	case flag.Value:
+		return true, target.Set(value)
+	}
+	return false, nil
+}
@@ -0,0 +1,103 @@
+package env
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testLevel implements flag.Value.
+type testLevel int
+
+func (l *testLevel) String() string { return "" }
+
+func (l *testLevel) Set(value string) error {
+	switch strings.ToLower(value) {
+	case "debug":
+		*l = 1
+	case "info":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+// testHostPort has a registered decoder.
+type testHostPort struct {
+	Host, Port string
+}
+
+// TestSetFieldValueCustomDecoders tests decoding with encoding.TextUnmarshaler, flag.Value and registered decoders.
+func TestSetFieldValueCustomDecoders(t *testing.T) {
+	RegisterDecoder(reflect.TypeOf(testHostPort{}), func(value string) (interface{}, error) {
+		host, port, err := net.SplitHostPort(value)
+		return testHostPort{Host: host, Port: port}, err
+	})
+	t.Cleanup(func() { RegisterDecoder(reflect.TypeOf(testHostPort{}), nil) })
+
+	type config struct {
+		IP       net.IP
+		Endpoint *url.URL
+		Started  time.Time
+		Level    testLevel
+		Addr     testHostPort
+		Peers    []net.IP
+	}
+	var cfg config
+	v := reflect.ValueOf(&cfg).Elem()
+
+	values := map[string]string{
+		"IP":       "10.0.0.1",
+		"Endpoint": "https://example.com/api",
+		"Started":  "2024-01-02T03:04:05Z",
+		"Level":    "DEBUG",
+		"Addr":     "localhost:8080",
+		"Peers":    "10.0.0.2,10.0.0.3",
+	}
+	for name, value := range values {
+		if err := setFieldValue(v.FieldByName(name), value, valueOptionsFromTag("")); err != nil {
+			t.Fatalf("setFieldValue(%s, %q) failed: %v", name, value, err)
+		}
+	}
+
+	if !cfg.IP.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("Expected IP 10.0.0.1, got %v", cfg.IP)
+	}
+	if cfg.Endpoint == nil || cfg.Endpoint.Host != "example.com" || cfg.Endpoint.Path != "/api" {
+		t.Errorf("Expected Endpoint https://example.com/api, got %v", cfg.Endpoint)
+	}
+	if !cfg.Started.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Expected Started 2024-01-02T03:04:05Z, got %v", cfg.Started)
+	}
+	if cfg.Level != 1 {
+		t.Errorf("Expected Level 1, got %d", cfg.Level)
+	}
+	if cfg.Addr != (testHostPort{Host: "localhost", Port: "8080"}) {
+		t.Errorf("Expected Addr {localhost 8080}, got %+v", cfg.Addr)
+	}
+	if len(cfg.Peers) != 2 || !cfg.Peers[1].Equal(net.ParseIP("10.0.0.3")) {
+		t.Errorf("Expected Peers [10.0.0.2 10.0.0.3], got %v", cfg.Peers)
+	}
+
+	if err := setFieldValue(v.FieldByName("Level"), "loud", valueOptionsFromTag("")); err == nil {
+		t.Errorf("Expected error for invalid flag.Value input, got nil")
+	}
+}
+
+// TestCustomDecoderStructIsLeaf tests that decodable structs are not walked as nested structs.
+func TestCustomDecoderStructIsLeaf(t *testing.T) {
+	type config struct {
+		Started time.Time `env:"STARTED"`
+		Created time.Time
+	}
+	var cfg config
+	fields := collectFields(reflect.ValueOf(&cfg).Elem(), namePrefix{})
+	if len(fields) != 2 || fields[0].Name != "Started" || fields[1].Name != "Created" {
+		t.Errorf("Expected leaf fields [Started Created], got %+v", fields)
+	}
+}
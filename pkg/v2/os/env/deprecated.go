@@ -0,0 +1,64 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/finiteloopme/goutils/pkg/log"
+)
+
+// isDeprecated reports whether the field has a `deprecated` tag.
+func isDeprecated(fs fieldSpec) bool {
+	deprecated, ok := fs.Field.Tag.Lookup(TagDeprecated)
+	return ok && deprecated != "false"
+}
+
+// deprecationWarnings returns a warning for each value provided to a deprecated field
+// under one of its aliases, or under any key if the field has no aliases.
+// Default values never warn.
+func deprecationWarnings(prefix string, fs fieldSpec, provided []SourceValue) []string {
+	if !isDeprecated(fs) {
+		return nil
+	}
+	reason := ""
+	if tag := fs.Field.Tag.Get(TagDeprecated); tag != "" && tag != "true" {
+		reason = ": " + tag
+	}
+
+	// Map each alias to the canonical name replacing it.
+	replacements := make(map[string]string)
+	for _, alias := range fs.EnvAliases {
+		replacements[strings.ToUpper(prefix+alias)] = strings.ToUpper(prefix + fs.EnvKey)
+	}
+	for _, alias := range fs.FlagAliases {
+		replacements["--"+alias] = "--" + fs.FlagName
+	}
+
+	var warnings []string
+	for _, p := range provided {
+		if p.Source == SourceDefault {
+			continue
+		}
+		if len(replacements) == 0 {
+			warnings = append(warnings, fmt.Sprintf("field %q is deprecated (%s %s)%s", fs.Name, p.Source, p.Key, reason))
+		} else if canonical, ok := replacements[p.Key]; ok {
+			warnings = append(warnings, fmt.Sprintf("field %q: %s is deprecated, use %s instead%s", fs.Name, p.Key, canonical, reason))
+		}
+	}
+	return warnings
+}
+
+// warnOnce logs a deprecation warning through pkg/log the first time the loader meets it,
+// so that a Watcher reloading the spec doesn't repeat it.
+func (l *Loader) warnOnce(warning string) {
+	l.warnedMu.Lock()
+	defer l.warnedMu.Unlock()
+	if l.warned[warning] {
+		return
+	}
+	if l.warned == nil {
+		l.warned = make(map[string]bool)
+	}
+	l.warned[warning] = true
+	log.Warnf("%s", warning)
+}
@@ -0,0 +1,144 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type aliasTestConfig struct {
+	Port    int    `env:"HTTP_PORT,SERVER_PORT" flag:"http-port,server-port" default:"8080" deprecated:"renamed in v2"`
+	Host    string `env:"HOST,ADDR"`
+	Legacy  string `env:"LEGACY" deprecated:"true"`
+	Verbose bool   `flag:"verbose,v"`
+}
+
+// TestAliases tests that aliases are looked up after the canonical name, and warn if deprecated.
+func TestAliases(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"APP_SERVER_PORT": "9090", "APP_ADDR": "example.com", "APP_LEGACY": "old"})
+
+	var cfg aliasTestConfig
+	report, err := l.LoadWithReport(context.Background(), "APP_", &cfg)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Port != 9090 {
+		t.Errorf("Expected Port 9090, got %d", cfg.Port)
+	}
+	if cfg.Host != "example.com" {
+		t.Errorf("Expected Host 'example.com', got %q", cfg.Host)
+	}
+	if key := report.Fields[0].Key; key != "APP_SERVER_PORT" {
+		t.Errorf("Expected Port key APP_SERVER_PORT, got %q", key)
+	}
+
+	want := []string{
+		`field "Port": APP_SERVER_PORT is deprecated, use APP_HTTP_PORT instead: renamed in v2`,
+		`field "Legacy" is deprecated (environment APP_LEGACY)`,
+	}
+	if !reflect.DeepEqual(report.Warnings, want) {
+		t.Errorf("Expected warnings %q, got %q", want, report.Warnings)
+	}
+	if !strings.Contains(report.String(), "WARNING: "+want[0]) {
+		t.Errorf("Expected report to list warnings, got:\n%s", report)
+	}
+}
+
+// TestAliasCanonicalWins tests that the canonical name takes precedence over its aliases without warning.
+func TestAliasCanonicalWins(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"HTTP_PORT": "1", "SERVER_PORT": "2"})
+
+	var cfg aliasTestConfig
+	report, err := l.LoadWithReport(context.Background(), "", &cfg)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Port != 1 {
+		t.Errorf("Expected Port 1, got %d", cfg.Port)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %q", report.Warnings)
+	}
+}
+
+// TestFlagAliases tests that flag aliases share the value of the canonical flag.
+func TestFlagAliases(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader([]string{"--server-port=7070", "-v"}, nil)
+
+	var cfg aliasTestConfig
+	report, err := l.LoadWithReport(context.Background(), "", &cfg)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Port != 7070 {
+		t.Errorf("Expected Port 7070, got %d", cfg.Port)
+	}
+	if !cfg.Verbose {
+		t.Errorf("Expected Verbose true, got %t", cfg.Verbose)
+	}
+	want := []string{`field "Port": --server-port is deprecated, use --http-port instead: renamed in v2`}
+	if !reflect.DeepEqual(report.Warnings, want) {
+		t.Errorf("Expected warnings %q, got %q", want, report.Warnings)
+	}
+
+	alias := l.flags().Lookup("server-port")
+	if alias == nil {
+		t.Fatalf("Expected flag --server-port to be defined")
+	}
+	if alias.Usage != "Deprecated alias of --http-port" {
+		t.Errorf("Expected deprecated alias usage, got %q", alias.Usage)
+	}
+	if usage := l.flags().Lookup("v").Usage; usage != "Alias of --verbose" {
+		t.Errorf("Expected alias usage, got %q", usage)
+	}
+}
+
+// TestSplitNames tests the parsing of comma separated env and flag tags.
+func TestSplitNames(t *testing.T) {
+	tests := []struct {
+		tag       string
+		canonical string
+		aliases   []string
+	}{
+		{"", "", nil},
+		{"PORT", "DB_PORT", nil},
+		{"PORT, DB_PORT_NUMBER", "DB_PORT", []string{"DB_DB_PORT_NUMBER"}},
+		{",PORT,", "DB_PORT", nil},
+	}
+	for _, tt := range tests {
+		canonical, aliases := splitNames(tt.tag, "DB_")
+		if canonical != tt.canonical || !reflect.DeepEqual(aliases, tt.aliases) {
+			t.Errorf("Expected %q %q for %q, got %q %q", tt.canonical, tt.aliases, tt.tag, canonical, aliases)
+		}
+	}
+}
+
+// TestDeprecationWarnedOnce tests that reloads don't log the same deprecation warning again.
+func TestDeprecationWarnedOnce(t *testing.T) {
+	var buf bytes.Buffer
+	logrus.SetOutput(&buf)
+	t.Cleanup(func() { logrus.SetOutput(os.Stderr) })
+
+	l := newTestLoader(nil, map[string]string{"SERVER_PORT": "9090"})
+	for i := 0; i < 3; i++ {
+		var cfg aliasTestConfig
+		report, err := l.LoadWithReport(context.Background(), "", &cfg)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if len(report.Warnings) != 1 {
+			t.Errorf("Expected 1 warning in report %d, got %q", i, report.Warnings)
+		}
+	}
+	if n := strings.Count(buf.String(), "SERVER_PORT is deprecated"); n != 1 {
+		t.Errorf("Expected the warning to be logged once, got %d times:\n%s", n, buf.String())
+	}
+}
@@ -0,0 +1,78 @@
+// Package env loads configuration into struct specifications from defaults, config
+// files, .env files, environment variables, secrets and command-line flags, driven by
+// struct tags (see the Tag constants). ProcessConfig describes the tags and the order
+// in which sources are applied; Loader and its options configure each source.
+//
+// # Config files and .env files
+//
+// Config files are given to NewLoader with WithConfigFiles, WithConfigEnv or WithConfigFlag
+// (e.g., --config); later files override earlier ones. Nested structs map to nested
+// tables keyed by the struct field's `file` tag or lower-cased name (e.g., db.host).
+//
+// Values from .env files are set in the process environment without overriding variables
+// already set, unless the loader is created with WithDotenvOverride; with WithDotenvIsolated
+// they are only visible to the loader. Malformed .env files are reported as errors.
+//
+// # Profiles
+//
+// An active profile (e.g., "prod"), read from APP_PROFILE or set with WithProfile, layers
+// .env.<profile> over each .env file, config.<profile>.yaml over each config file, and
+// `default.<profile>` tags over `default` tags, flag defaults included. It is recorded
+// in the Report.
+//
+// # Secrets
+//
+// Secret names may select a provider by URI scheme: gcp://, file:// (relative to /run/secrets)
+// or env:// (another environment variable). Loaders can replace the default provider and add schemes.
+// Google Secret Manager names may be short ("db-password", or "db-password@3" to pin a version),
+// resolved against the GCP project. Distinct secrets are fetched once per Load, concurrently,
+// each within a timeout (WithSecretConcurrency, WithSecretTimeout); WithSecretCacheTTL
+// reuses fetched values across loads. A secret holding a JSON object, YAML mapping or
+// dotenv lines can feed several fields with key selectors (e.g., `secret:"db#password"`,
+// `secret:"db#replica.host"`); its payload is fetched and parsed once.
+//
+// # Field names and types
+//
+// Nested structs and pointers to structs are walked recursively. A `prefix` tag on
+// the struct field namespaces the env, flag and secret names of its fields:
+// `prefix:"DB_"` maps `env:"HOST"` to DB_HOST, `flag:"host"` to --db.host and a
+// short `secret:"password"` to db-password. Nil struct pointers are allocated.
+//
+// Keys can be renamed without breaking deployments: `env:"HTTP_PORT,SERVER_PORT"` and
+// `flag:"http-port,server-port"` also accept the old names, and a `deprecated` tag logs a
+// warning the first time a value is loaded from them; Report.Warnings lists them on every load.
+//
+// Slice and map fields are split on the `separator` tag (default ",") and map entries
+// on the `kvseparator` tag (default ":"), e.g. `ORIGINS=a.com,b.com` or `LABELS=env:prod,team:core`.
+// Their flags are repeatable (--origin a.com --origin b.com).
+//
+// Fields whose type implements encoding.TextUnmarshaler or flag.Value, or has a decoder
+// registered with RegisterDecoder, are decoded with it (e.g. net.IP, *url.URL, time.Time).
+//
+// # Expansion
+//
+// Loaders created with WithExpansion, and fields tagged `expand:"true"`, expand ${NAME}
+// and ${NAME:-fallback} references in values from any source once all fields are loaded
+// (e.g., `default:"${HOME}/.cache/app"`). NAME is the dotted path or environment variable
+// of another field, else an environment variable; "$$" is a literal "$". Values from
+// secrets are only expanded in fields tagged `expand:"true"`.
+//
+// # Validation
+//
+// Values set by a source, even zero ones (e.g., PORT=0), are checked against the
+// constraint tags min, max, len, oneof, regex, url, hostport and file_exists (e.g.,
+// `min:"1" max:"65535"`, `oneof:"debug info"`). Empty strings (e.g., LOG_URL=) are not
+// checked: they leave an optional field empty, and fail a required one. Finally, if
+// the spec implements Validator, its Validate method is called for cross-field rules.
+//
+// # Beyond loading
+//
+// Watch keeps a config up to date in long-running services. ExportEnv, ExportFlags,
+// ExportYAML and ExportJSON render a loaded spec back into those sources, e.g. for child
+// processes, and Redacted prints it without its sensitive values. NewEnvconfigLoader loads
+// specs written for github.com/kelseyhightower/envconfig. CLIs with subcommands bind a spec
+// per subcommand with ProcessSubcommand. Small tools can read single variables without a
+// struct with the typed accessors String, Int, Duration, Bool, Slice and Get, e.g.
+// env.Int("PORT", env.Default("8080")). The envtest subpackage loads specs against
+// hermetic fixtures in tests.
+package env
@@ -0,0 +1,136 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// dotenvFiles returns the .env files to load.
+func (l *Loader) dotenvFiles() []string {
+	if l.dotenvDisabled {
+		return nil
+	}
+	if len(l.dotenvPaths) > 0 {
+		return l.dotenvPaths
+	}
+	return []string{".env"}
+}
+
+// loadDotenv reads the .env files, then their overlays for the active profile
+// (e.g., .env.prod); later files override earlier ones and missing files are skipped.
+// It returns the active profile, which may be set in the .env files, the files it
+// looked for, and the errors reading the files that exist.
+//
+// Unless the loader is isolated, the values are set in the process environment. Like
+// godotenv.Load, variables already set are not overridden without WithDotenvOverride,
+// except those set from a .env file by an earlier Load of this loader: those follow
+// edits to the file, so that a reload picks them up.
+func (l *Loader) loadDotenv() (profile string, files []string, errs []FieldError) {
+	files = l.dotenvFiles()
+	values, errs := readDotenvFiles(files)
+	if profile = l.activeProfile(values); profile != "" {
+		overlays := make([]string, len(files))
+		for i, path := range files {
+			overlays[i] = profileDotenvPath(path, profile)
+		}
+		overlayValues, overlayErrs := readDotenvFiles(overlays)
+		for key, value := range overlayValues {
+			values[key] = value
+		}
+		files = append(files, overlays...)
+		errs = append(errs, overlayErrs...)
+	}
+
+	l.dotenvMu.Lock()
+	defer l.dotenvMu.Unlock()
+	if l.dotenvIsolated {
+		l.dotenvValues = values
+		return profile, files, errs
+	}
+
+	if l.dotenvSet == nil {
+		l.dotenvSet = make(map[string]string)
+		l.dotenvPrev = make(map[string]string)
+	}
+	for key, value := range values {
+		current, isSet := os.LookupEnv(key)
+		previous, owned := l.dotenvSet[key]
+		if external := isSet && (!owned || current != previous); external {
+			if !l.dotenvOverride {
+				continue // Set outside of the .env files
+			}
+			l.dotenvPrev[key] = current
+		}
+		os.Setenv(key, value)
+		l.dotenvSet[key] = value
+	}
+	for key, previous := range l.dotenvSet {
+		if _, ok := values[key]; !ok {
+			// Removed from the .env files: restore the overridden value, if any
+			if current, isSet := os.LookupEnv(key); isSet && current == previous {
+				if prev, overridden := l.dotenvPrev[key]; overridden {
+					os.Setenv(key, prev)
+				} else {
+					os.Unsetenv(key)
+				}
+			}
+			delete(l.dotenvSet, key)
+			delete(l.dotenvPrev, key)
+		}
+	}
+	return profile, files, errs
+}
+
+// readDotenvFiles reads and merges the .env files that exist; later files override
+// earlier ones. Files that cannot be read or parsed are reported and skipped.
+func readDotenvFiles(paths []string) (map[string]string, []FieldError) {
+	values := make(map[string]string)
+	var errs []FieldError
+	for _, path := range paths {
+		fileValues, err := godotenv.Read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, FieldError{
+				Source: SourceDotenv,
+				Key:    path,
+				Kind:   ErrParse,
+				Err:    err,
+				msg:    fmt.Sprintf("dotenv file %q: %v", path, err),
+			})
+			continue
+		}
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+	return values, errs
+}
+
+// lookup returns the value of the environment variable key, as seen by the loader.
+func (l *Loader) lookup(key string) (string, bool) {
+	value, _, ok := l.lookupVar(key)
+	return value, ok
+}
+
+// lookupVar returns the value of the environment variable key, as seen by the loader:
+// the environment, combined with the values of isolated .env files. It also reports
+// whether the value comes from a .env file.
+func (l *Loader) lookupVar(key string) (value string, fromDotenv, ok bool) {
+	l.dotenvMu.Lock()
+	dotenvValue, inDotenv := l.dotenvValues[key]
+	setValue, set := l.dotenvSet[key]
+	l.dotenvMu.Unlock()
+
+	if inDotenv && l.dotenvOverride {
+		return dotenvValue, true, true
+	}
+	if value, ok := l.lookupEnv(key); ok {
+		return value, set && value == setValue, true
+	}
+	return dotenvValue, inDotenv, inDotenv
+}
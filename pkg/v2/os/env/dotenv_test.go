@@ -0,0 +1,87 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type dotenvTestConfig struct {
+	Host string `env:"DOTENV_TEST_HOST" default:"localhost"`
+	Port int    `env:"DOTENV_TEST_PORT"`
+	User string `env:"DOTENV_TEST_USER"`
+}
+
+// TestDotenvIsolated tests that isolated .env files are merged in order, leave the
+// process environment untouched and are reported as the dotenv source.
+func TestDotenvIsolated(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	base := writeTestFile(t, dir, "base.env", "DOTENV_TEST_HOST=base\nDOTENV_TEST_PORT=80\n")
+	local := writeTestFile(t, dir, "local.env", "DOTENV_TEST_PORT=8080\n")
+
+	l := newTestLoader(nil, map[string]string{"DOTENV_TEST_HOST": "from-env"},
+		WithDotenvPaths(base, dir+"/missing.env", local), WithDotenvIsolated())
+	var cfg dotenvTestConfig
+	report, err := l.LoadWithReport(context.Background(), "", &cfg)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Host != "from-env" || cfg.Port != 8080 {
+		t.Errorf("Expected Host 'from-env' and Port 8080, got %+v", cfg)
+	}
+	if fr := report.Field("Port"); fr == nil || fr.Source != SourceDotenv {
+		t.Errorf("Expected Port from the dotenv source, got %+v", fr)
+	}
+	if _, ok := os.LookupEnv("DOTENV_TEST_PORT"); ok {
+		t.Errorf("Expected the process environment to be left untouched")
+	}
+
+	l = newTestLoader(nil, map[string]string{"DOTENV_TEST_HOST": "from-env"},
+		WithDotenvPaths(base, local), WithDotenvIsolated(), WithDotenvOverride())
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Host != "base" {
+		t.Errorf("Expected .env to override the environment, got Host %q", cfg.Host)
+	}
+}
+
+// TestDotenvOverrideProcessEnv tests that overridden variables are restored once
+// removed from the .env file.
+func TestDotenvOverrideProcessEnv(t *testing.T) {
+	t.Setenv("DOTENV_TEST_USER", "original")
+	path := writeTestFile(t, t.TempDir(), ".env", "DOTENV_TEST_USER=from-file\n")
+
+	l := newTestLoader(nil, nil, WithLookupEnv(os.LookupEnv), WithDotenvPaths(path), WithDotenvOverride())
+	var cfg dotenvTestConfig
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.User != "from-file" || os.Getenv("DOTENV_TEST_USER") != "from-file" {
+		t.Errorf("Expected User 'from-file', got %q", cfg.User)
+	}
+
+	writeTestFile(t, "", path, "\n")
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := os.Getenv("DOTENV_TEST_USER"); got != "original" {
+		t.Errorf("Expected DOTENV_TEST_USER to be restored to 'original', got %q", got)
+	}
+}
+
+// TestDotenvParseError tests that malformed .env files are reported.
+func TestDotenvParseError(t *testing.T) {
+	t.Parallel()
+	path := writeTestFile(t, t.TempDir(), "bad.env", "DOTENV_TEST_HOST=\"unterminated\n")
+
+	l := newTestLoader(nil, nil, WithDotenvPaths(path), WithDotenvIsolated())
+	var cfg dotenvTestConfig
+	err := l.Load(context.Background(), "", &cfg)
+	if !errors.Is(err, ErrParse) || !strings.Contains(err.Error(), "dotenv file") {
+		t.Errorf("Expected a dotenv parse error, got %v", err)
+	}
+}
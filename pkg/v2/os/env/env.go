@@ -3,25 +3,23 @@ package env
 import (
 	"context"
 	"errors"
-	"flag"
 	"fmt"
-	"os"
 	"reflect"
 	"strconv"
-	"strings"
 	"time"
-
-	secretmanager "cloud.google.com/go/secretmanager/apiv1"
-	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
-	"github.com/joho/godotenv"
 )
 
 const (
-	// TagEnv specifies the environment variable name.
+	// TagEnv specifies the environment variable name. Further comma separated names are
+	// aliases looked up when the first, canonical one is not set (e.g., "HTTP_PORT,SERVER_PORT").
 	TagEnv = "env"
-	// TagSecret specifies the Google Secret Manager secret name (e.g., "projects/PROJECT_ID/secrets/SECRET_NAME/versions/latest").
+	// TagSecret specifies the secret name, resolved by the loader's secret provider
+	// (Google Secret Manager by default, e.g., "projects/PROJECT_ID/secrets/SECRET_NAME/versions/latest"),
+	// or by the provider for its URI scheme (e.g., "file://db-password", "env://DB_PASSWORD").
+	// A "#key.path" suffix selects a value of a JSON, YAML or dotenv payload (e.g., "db#password").
 	TagSecret = "secret"
-	// TagFlag specifies the command-line flag name.
+	// TagFlag specifies the command-line flag name. Further comma separated names are
+	// aliases defined alongside the first, canonical one (e.g., "http-port,server-port").
 	TagFlag = "flag"
 	// TagDefault specifies the default value if no other source provides one.
 	TagDefault = "default"
@@ -29,414 +27,128 @@ const (
 	TagRequired = "required"
 	// TagIgnored specifies that the field should be ignored by the loader.
 	TagIgnored = "ignored"
+	// TagPrefix specifies the name prefix for the fields of a nested struct (e.g., "DB_").
+	TagPrefix = "prefix"
+	// TagSeparator specifies the separator between slice elements and map entries. Default is ",".
+	TagSeparator = "separator"
+	// TagKVSeparator specifies the separator between the key and value of a map entry. Default is ":".
+	TagKVSeparator = "kvseparator"
+	// TagEnvconfig specifies the environment variable name in envconfig mode (WithEnvconfig).
+	TagEnvconfig = "envconfig"
+	// TagSplitWords names the environment variable of a field from the words of its name
+	// in envconfig mode (e.g., MaxRetries is MAX_RETRIES rather than MAXRETRIES).
+	TagSplitWords = "split_words"
+	// TagSensitive specifies that the field's value must be redacted in reports, errors and by Redacted.
+	// Implied by TagSecret. Values expanded from a sensitive field (e.g., `default:"${PASSWORD}"`) are
+	// redacted in reports and errors, but Redacted only knows the tags: tag such fields too.
+	TagSensitive = "sensitive"
+	// TagFile specifies the config file key. Defaults to the `yaml` or `json` tag name, else the lower-cased field name.
+	TagFile = "file"
+	// TagReload specifies if a Watcher may change the field after the initial load. Default is "true".
+	TagReload = "reload"
+	// TagExpand specifies if ${NAME} references in the field's value are expanded. Default is
+	// "false", or "true" for loaders created with WithExpansion except for values from secrets.
+	TagExpand = "expand"
+	// TagDescription specifies a description of the field, used in flag usage and generated docs.
+	TagDescription = "description"
+	// TagDeprecated specifies that the env and flag aliases of the field are deprecated: values
+	// loaded from them log a warning through pkg/log, once per loader, which includes the tag value
+	// unless it is "true" (e.g., "renamed in v2"). On a field without aliases, the field itself is deprecated.
+	TagDeprecated = "deprecated"
+
+	// TagMin specifies the minimum value of numbers and durations, or the minimum length of strings and collections.
+	TagMin = "min"
+	// TagMax specifies the maximum value of numbers and durations, or the maximum length of strings and collections.
+	TagMax = "max"
+	// TagLen specifies the exact length of strings and collections.
+	TagLen = "len"
+	// TagOneOf specifies the space separated list of allowed values (e.g., "debug info warn").
+	TagOneOf = "oneof"
+	// TagRegex specifies a regular expression the value must match.
+	TagRegex = "regex"
+	// TagURL specifies that the value must be an absolute URL.
+	TagURL = "url"
+	// TagHostPort specifies that the value must be a host:port address.
+	TagHostPort = "hostport"
+	// TagFileExists specifies that the value must be the path of an existing file or directory.
+	TagFileExists = "file_exists"
 )
 
 var (
-	// smClient is a cached Secret Manager client.
-	smClient *secretmanager.Client
 	// errInvalidSpecification indicates that the spec argument was not a pointer to a struct.
 	errInvalidSpecification = errors.New("specification must be a pointer to a struct")
-	// flagsDefined ensures we only define flags once per application run.
-	flagsDefined = false
-	// flagValues holds the pointers to the variables bound to the flags.
-	// We need this because flag.Xxx returns the pointer, and we define them before parsing.
-	flagValues = make(map[string]interface{})
-	// flagWasSet tracks which flags were explicitly set on the command line.
-	flagWasSet = make(map[string]bool)
 )
 
-// initSecretManagerClient initializes the Secret Manager client if needed.
-// It assumes Application Default Credentials (ADC) are configured correctly.
-func initSecretManagerClient(ctx context.Context) error {
-	if smClient == nil {
-		var err error
-		smClient, err = secretmanager.NewClient(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to create secret manager client: %w", err)
-		}
-	}
-	return nil
-}
-
-// CloseSecretManagerClient closes the cached Secret Manager client if it was initialized.
-// It's good practice to call this when the application shuts down.
-func CloseSecretManagerClient() error {
-	if smClient != nil {
-		err := smClient.Close()
-		smClient = nil // Reset client after closing
-		return err
-	}
-	return nil
-}
-
-// defineFlags defines command-line flags based on struct tags.
-// It uses the global flag set (flag.CommandLine).
-func defineFlags(spec interface{}) error {
-	if flagsDefined {
-		return nil // Avoid defining flags multiple times
-	}
-
-	specValue := reflect.ValueOf(spec)
-	specElem := specValue.Elem()
-	specType := specElem.Type()
-
-	var flagDefinitionErrors []string
-
-	for i := 0; i < specElem.NumField(); i++ {
-		field := specElem.Field(i)
-		fieldType := specType.Field(i)
-
-		// Skip unexported fields or ignored fields
-		if !field.CanSet() || fieldType.Tag.Get(TagIgnored) == "true" {
-			continue
-		}
-
-		flagName := fieldType.Tag.Get(TagFlag)
-		if flagName == "" {
-			continue // No flag defined for this field
-		}
-
-		if flg := flag.Lookup(flagName); flg != nil {
-			// Flag already defined on command line
-			flagWasSet[flagName] = true      // Mark as set
-			flagValues[flagName] = flg.Value // Set the value
-			continue                         // Skip this field
-		}
-
-		// Prevent duplicate flag definition
-		if _, exists := flagValues[flagName]; exists {
-			// This could happen if Load is called multiple times with overlapping flag names
-			// or if the user manually defined a flag with the same name.
-			// We'll allow it but maybe log a warning in a real app.
-			// return fmt.Errorf("flag %q is already defined", flagName)
-			continue
-		}
-
-		defaultValueStr := fieldType.Tag.Get(TagDefault)
-		usage := fmt.Sprintf("Set value for %s", fieldType.Name) // Basic usage message
-		envVar := fieldType.Tag.Get(TagEnv)
-		if envVar != "" {
-			usage = fmt.Sprintf("%s (env: %s)", usage, envVar)
-		}
-
-		// Define the flag based on the field type
-		kind := field.Kind()
-		// If field is a pointer, get the underlying type
-		if kind == reflect.Ptr {
-			kind = field.Type().Elem().Kind()
-		}
-
-		switch kind {
-		case reflect.String:
-			flagValues[flagName] = flag.String(flagName, defaultValueStr, usage)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			// Handle time.Duration specifically
-			if field.Type() == reflect.TypeOf(time.Duration(0)) || (field.Kind() == reflect.Ptr && field.Type().Elem() == reflect.TypeOf(time.Duration(0))) {
-				defaultDuration := time.Duration(0)
-				if defaultValueStr != "" {
-					var err error
-					defaultDuration, err = time.ParseDuration(defaultValueStr)
-					if err != nil {
-						flagDefinitionErrors = append(flagDefinitionErrors, fmt.Sprintf("field %q (flag %q): invalid default duration format %q: %v", fieldType.Name, flagName, defaultValueStr, err))
-						continue // Skip defining this flag
-					}
-				}
-				flagValues[flagName] = flag.Duration(flagName, defaultDuration, usage)
-			} else { // Handle regular integers
-				defaultInt := int64(0)
-				if defaultValueStr != "" {
-					var err error
-					defaultInt, err = strconv.ParseInt(defaultValueStr, 0, 64) // Parse as int64 for flag
-					if err != nil {
-						flagDefinitionErrors = append(flagDefinitionErrors, fmt.Sprintf("field %q (flag %q): invalid default integer format %q: %v", fieldType.Name, flagName, defaultValueStr, err))
-						continue // Skip defining this flag
-					}
-				}
-				// Use flag.Int for simplicity if the target is int, otherwise keep int64
-				if kind == reflect.Int {
-					flagValues[flagName] = flag.Int(flagName, int(defaultInt), usage)
-				} else {
-					// For int8, int16, int32, int64, the flag package uses int64 internally
-					flagValues[flagName] = flag.Int64(flagName, defaultInt, usage)
-				}
-
-			}
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			defaultUint := uint64(0)
-			if defaultValueStr != "" {
-				var err error
-				defaultUint, err = strconv.ParseUint(defaultValueStr, 0, 64) // Parse as uint64 for flag
-				if err != nil {
-					flagDefinitionErrors = append(flagDefinitionErrors, fmt.Sprintf("field %q (flag %q): invalid default unsigned integer format %q: %v", fieldType.Name, flagName, defaultValueStr, err))
-					continue // Skip defining this flag
-				}
-			}
-			if kind == reflect.Uint {
-				flagValues[flagName] = flag.Uint(flagName, uint(defaultUint), usage)
-			} else {
-				flagValues[flagName] = flag.Uint64(flagName, defaultUint, usage)
-			}
-
-		case reflect.Bool:
-			defaultBool := false
-			if defaultValueStr != "" {
-				var err error
-				defaultBool, err = strconv.ParseBool(defaultValueStr)
-				if err != nil {
-					flagDefinitionErrors = append(flagDefinitionErrors, fmt.Sprintf("field %q (flag %q): invalid default boolean format %q: %v", fieldType.Name, flagName, defaultValueStr, err))
-					continue // Skip defining this flag
-				}
-			}
-			flagValues[flagName] = flag.Bool(flagName, defaultBool, usage)
-		case reflect.Float32, reflect.Float64:
-			defaultFloat := float64(0)
-			if defaultValueStr != "" {
-				var err error
-				defaultFloat, err = strconv.ParseFloat(defaultValueStr, 64) // Parse as float64 for flag
-				if err != nil {
-					flagDefinitionErrors = append(flagDefinitionErrors, fmt.Sprintf("field %q (flag %q): invalid default float format %q: %v", fieldType.Name, flagName, defaultValueStr, err))
-					continue // Skip defining this flag
-				}
-			}
-			flagValues[flagName] = flag.Float64(flagName, defaultFloat, usage)
-		// Add cases for other flag types if needed (e.g., slices using flag.Func)
-		default:
-			flagDefinitionErrors = append(flagDefinitionErrors, fmt.Sprintf("field %q (flag %q): unsupported type for flag: %s", fieldType.Name, flagName, kind))
-		}
-	} // End field loop
-
-	if len(flagDefinitionErrors) > 0 {
-		return fmt.Errorf("flag definition errors:\n - %s", strings.Join(flagDefinitionErrors, "\n - "))
-	}
-
-	flagsDefined = true
-	return nil
-}
-
 // ProcessConfig processes configuration from various sources into the provided struct specification.
 //
 // The spec argument must be a pointer to a struct. Fields in the struct can use
-// tags (env, secret, flag, default, required, ignored) to control loading.
+// tags (env, secret, flag, default, required, ignored) to control loading; the package
+// documentation covers nested structs, collections, profiles, expansion and validation.
 //
 // Load will **define and parse** command-line flags based on `flag` tags.
 // Call this function *instead* of manually defining/parsing flags related to the config struct.
 //
+// Flags are parsed against all of os.Args[1:] each time a spec defines new flags, so every
+// flag on the command line must belong to the spec being loaded or to one loaded before it.
+// flag.CommandLine exits the process on an unknown flag: ProcessConfig supports a single
+// spec with flags per program. Gather them into one struct, or bind each spec to a flag set
+// of its own with ProcessSubcommand or NewLoader and WithFlagSet.
+//
 // Sources are processed in the following order (later sources override earlier ones):
 // 1. Default values (`default` tag) - Also used as defaults for flags.
-// 2. .env file (if present)
-// 3. Environment variables (`env` tag)
-// 4. Google Secret Manager (`secret` tag) - Requires ADC or explicit credentials.
-// 5. Command-line flags (`flag` tag)
+// 2. Config files (`file` tag) - YAML, JSON or TOML; only read by loaders configured with config files.
+// 3. .env files (./.env, or WithDotenvPaths; missing files are skipped)
+// 4. Environment variables (`env` tag)
+// 5. Secrets (`secret` tag) - Google Secret Manager by default, which requires ADC or explicit credentials.
+// 6. Command-line flags (`flag` tag)
 //
 // A prefix can be provided to namespace environment variables (e.g., "APP_").
-// Required fields (`required:"true"`) must have a value after processing all sources.
+//
+// Required fields (`required:"true"`) must have a value after processing all sources,
+// and values are then checked against the constraint tags and the Validator interface.
+// All failures are reported together in a *ConfigError; use errors.Is with ErrRequired,
+// ErrParse, ErrSecretAccess, ErrUnsupportedType or ErrValidation to tell them apart.
+//
+// ProcessConfig is a thin wrapper over a default Loader bound to flag.CommandLine and
+// os.Args[1:]. Use NewLoader for an isolated flag set, arguments or environment.
 //
 // Example struct field:
 //
 //		APIKey string `env:"API_KEY" secret:"projects/p/secrets/s/versions/1" required:"true"`
 //	 Host   string `flag:"host" default:"localhost"`
+//	 DB     struct {
+//	     Host string `env:"HOST" flag:"host" default:"localhost"`
+//	 } `prefix:"DB_"`
 func ProcessConfig(ctx context.Context, prefix string, spec interface{}) error {
-	// --- Validation ---
-	specValue := reflect.ValueOf(spec)
-	if specValue.Kind() != reflect.Ptr || specValue.IsNil() {
-		return errInvalidSpecification
-	}
-	specElem := specValue.Elem()
-	if specElem.Kind() != reflect.Struct {
-		return errInvalidSpecification
-	}
-	specType := specElem.Type()
-
-	// --- Define Flags (if not already done) ---
-	if err := defineFlags(spec); err != nil {
-		return fmt.Errorf("error defining flags: %w", err)
-	}
-
-	// --- Parse Flags (if not already parsed) ---
-	// This ensures flags are parsed only once, even if Load is called multiple times.
-	// It uses the default CommandLine flag set.
-	if !flag.Parsed() {
-		flag.Parse()
-		// Record which flags were actually set by the user
-		flag.Visit(func(f *flag.Flag) {
-			flagWasSet[f.Name] = true
-		})
-	}
-
-	// --- Load Other Sources ---
-	// 1. Load .env file (ignore if not found)
-	_ = godotenv.Load() // Best effort
-
-	// 2. Prepare for Secret Manager (initialize client later if needed)
-	var smErr error
-	needsSecretManager := false
-	for i := 0; i < specElem.NumField(); i++ {
-		if _, ok := specType.Field(i).Tag.Lookup(TagSecret); ok {
-			needsSecretManager = true
-			break
-		}
-	}
-	if needsSecretManager {
-		smErr = initSecretManagerClient(ctx)
-		// We don't return immediately on error here, as other sources might still work.
-		// The error will be checked when fetching a specific secret.
-	}
-
-	var processingErrors []string
-
-	// --- Process Fields ---
-	for i := 0; i < specElem.NumField(); i++ {
-		field := specElem.Field(i)
-		fieldType := specType.Field(i)
-
-		// Skip unexported fields or ignored fields
-		if !field.CanSet() || fieldType.Tag.Get(TagIgnored) == "true" {
-			continue
-		}
-
-		var valueStr string
-		var found bool
-		var source string // Keep track of where the value came from (for debugging/info)
-
-		// --- 1. Apply Default Value ---
-		defaultValue := fieldType.Tag.Get(TagDefault)
-		if defaultValue != "" {
-			valueStr = defaultValue
-			found = true
-			source = "default"
-		}
-
-		// --- 2. Load from Environment Variable (from .env or actual env) ---
-		envKey := fieldType.Tag.Get(TagEnv)
-		if envKey != "" {
-			envFullName := strings.ToUpper(prefix + envKey)
-			if val, ok := os.LookupEnv(envFullName); ok {
-				valueStr = val
-				found = true
-				source = "environment"
-			}
-		}
-
-		// --- 3. Load from Secret Manager ---
-		secretName := fieldType.Tag.Get(TagSecret)
-		if secretName != "" {
-			if smErr != nil {
-				// Record error from client initialization if we needed it
-				processingErrors = append(processingErrors, fmt.Sprintf("field %q: failed to initialize secret manager client: %v", fieldType.Name, smErr))
-			} else if smClient == nil {
-				// Should not happen if needsSecretManager was true and init succeeded, but check anyway
-				processingErrors = append(processingErrors, fmt.Sprintf("field %q: secret manager client not initialized", fieldType.Name))
-			} else {
-				secretValue, err := accessSecretVersion(ctx, secretName)
-				if err != nil {
-					// Don't fail immediately, maybe another source worked or it's not required
-					processingErrors = append(processingErrors, fmt.Sprintf("field %q: failed to access secret %q: %v", fieldType.Name, secretName, err))
-				} else {
-					valueStr = secretValue
-					found = true
-					source = "secret"
-				}
-			}
-		}
-
-		// --- 4. Load from Command-line Flag ---
-		flagName := fieldType.Tag.Get(TagFlag)
-		// Check if the flag was *defined* for this field AND *set* on the command line
-		if flagName != "" {
-			if pointer, defined := flagValues[flagName]; defined && flagWasSet[flagName] {
-				// Get the value from the pointer stored during flag definition
-				// Need to use reflection to get the underlying value from the interface{} pointer
-				ptrValue := reflect.ValueOf(pointer) // e.g., ValueOf(**string)
-				if ptrValue.IsValid() && ptrValue.Kind() == reflect.Ptr && !ptrValue.IsNil() {
-					actualValue := ptrValue.Elem().Interface() // e.g., value of type string, int, bool etc.
-					// Convert the actual value back to string for setFieldValue
-					valueStr = fmt.Sprintf("%v", actualValue)
-					found = true
-					source = "flag"
-				} else {
-					// This shouldn't happen if defineFlags worked correctly
-					processingErrors = append(processingErrors, fmt.Sprintf("field %q: internal error retrieving value for flag %q", fieldType.Name, flagName))
-				}
-			} else if !defined && flagWasSet[flagName] {
-				// Flag was set but somehow not defined by our logic (e.g. user defined it manually)
-				// We could potentially try flag.Lookup here as a fallback, but it might indicate an issue.
-				// For now, we rely on flags being defined via `defineFlags`.
-				processingErrors = append(processingErrors, fmt.Sprintf("field %q: flag %q was set but not defined by config loader", fieldType.Name, flagName))
-			}
-		}
-
-		// --- Set Field Value ---
-		if found {
-			// fmt.Printf("Debug: Setting field %s from %s with value: %q\n", fieldType.Name, source, valueStr) // Optional debug line
-			if err := setFieldValue(field, valueStr); err != nil {
-				processingErrors = append(processingErrors, fmt.Sprintf("field %q (source: %s): error setting value '%s': %v", fieldType.Name, source, valueStr, err))
-				continue // Skip required check if setting failed
-			}
-		}
-
-		// --- Check Required ---
-		required := fieldType.Tag.Get(TagRequired)
-		// Check IsZero AFTER attempting to set. Handles cases where the loaded value IS the zero value (e.g., port 0, empty string).
-		// If 'found' is false, it definitely wasn't set. If 'found' is true, check if the resulting field value is zero.
-		if required == "true" && (!found || field.IsZero()) {
-			// Construct a more informative error message
-			errMsg := fmt.Sprintf("field %q is required but was not provided", fieldType.Name)
-			if found && field.IsZero() { // It was found, but the value resulted in zero
-				errMsg = fmt.Sprintf("field %q is required but received zero value (source: %s, raw value: '%s')", fieldType.Name, source, valueStr)
-			}
-			envDetail := ""
-			if envKey != "" {
-				envDetail = fmt.Sprintf(" (env: %s%s)", prefix, envKey)
-			}
-			flagDetail := ""
-			if flagName != "" {
-				flagDetail = fmt.Sprintf(" (flag: --%s)", flagName)
-			}
-			secretDetail := ""
-			if secretName != "" {
-				secretDetail = fmt.Sprintf(" (secret: %s)", secretName)
-			}
-			processingErrors = append(processingErrors, fmt.Sprintf("%s%s%s%s", errMsg, envDetail, flagDetail, secretDetail))
-		}
-	} // End field loop
-
-	if len(processingErrors) > 0 {
-		return fmt.Errorf("config loading errors:\n - %s", strings.Join(processingErrors, "\n - "))
-	}
-
-	return nil
+	return defaultLoader.Load(ctx, prefix, spec)
 }
 
-// accessSecretVersion fetches the payload of the given secret version.
-func accessSecretVersion(ctx context.Context, name string) (string, error) {
-	if smClient == nil {
-		return "", errors.New("secret manager client not initialized")
-	}
-
-	// Build the request.
-	req := &secretmanagerpb.AccessSecretVersionRequest{
-		Name: name,
-	}
-
-	// Call the API.
-	result, err := smClient.AccessSecretVersion(ctx, req)
-	if err != nil {
-		return "", fmt.Errorf("failed to access secret version %q: %w", name, err)
-	}
-
-	// Secret Manager payloads are limited to 64KiB.
-	// Ensure the payload isn't excessively large. Consider adding checks if needed.
-
-	return string(result.Payload.Data), nil
+// ProcessConfigWithReport processes configuration like ProcessConfig and returns a
+// Report of the source each field got its value from, suitable for logging at startup:
+//
+//	report, err := env.ProcessConfigWithReport(ctx, "APP_", &cfg)
+//	log.Info("configuration:\n" + report.String())
+func ProcessConfigWithReport(ctx context.Context, prefix string, spec interface{}) (*Report, error) {
+	return defaultLoader.LoadWithReport(ctx, prefix, spec)
 }
 
 // setFieldValue converts the string value and sets it on the reflect.Value field.
-// Supports basic types: string, int, int64, uint, uint64, bool, float64, time.Duration, and pointers to these.
-func setFieldValue(field reflect.Value, value string) error {
+// Supports basic types: string, int, int64, uint, uint64, bool, float64, time.Duration, pointers to these,
+// and slices and maps of these split according to opts. Types with a registered decoder, or implementing
+// encoding.TextUnmarshaler or flag.Value (e.g. net.IP, url.URL, time.Time), are decoded by those first.
+func setFieldValue(field reflect.Value, value string, opts valueOptions) error {
 	if !field.CanSet() {
 		return errors.New("field cannot be set")
 	}
 
+	// Registered decoders, encoding.TextUnmarshaler and flag.Value take precedence over built-in kinds
+	if handled, err := decodeCustom(field, value); handled {
+		if err != nil {
+			return fmt.Errorf("invalid %s value %q: %w", field.Type(), value, err)
+		}
+		return nil
+	}
+
 	fieldType := field.Type()
 
 	// If the field is a pointer, allocate memory if nil and set the pointed-to value
@@ -446,7 +158,7 @@ func setFieldValue(field reflect.Value, value string) error {
 			field.Set(reflect.New(fieldType.Elem()))
 		}
 		// Dereference the pointer and call setFieldValue recursively on the element
-		return setFieldValue(field.Elem(), value)
+		return setFieldValue(field.Elem(), value, opts)
 	}
 
 	// Handle non-pointer types
@@ -499,11 +211,12 @@ func setFieldValue(field reflect.Value, value string) error {
 			return fmt.Errorf("invalid float format %q: %w", value, err)
 		}
 		field.SetFloat(floatValue)
-	// Add cases for other types like slices, maps, etc. as needed
-	// case reflect.Slice:
-	// case reflect.Map:
+	case reflect.Slice:
+		return setSliceValue(field, value, opts)
+	case reflect.Map:
+		return setMapValue(field, value, opts)
 	default:
-		return fmt.Errorf("unsupported field type %s", fieldType.Kind())
+		return unsupportedTypeError{fieldType.Kind()}
 	}
 	return nil
 }
@@ -560,18 +273,9 @@ func main() {
 
 
 	// --- Use Configuration ---
-	fmt.Printf("Configuration loaded successfully:\n")
-	fmt.Printf("  Host: %s\n", cfg.ServerHost)
-	fmt.Printf("  Port: %d\n", cfg.ServerPort)
-	fmt.Printf("  API Key: %s\n", "***REDACTED***") // Don't print secrets!
-	fmt.Printf("  Timeout: %v\n", cfg.Timeout)
-	fmt.Printf("  Retries: %d\n", cfg.Retries)
-	fmt.Printf("  Debug Mode: %t\n", cfg.DebugMode)
-	if cfg.OptionalVal != nil {
-		fmt.Printf("  Optional Value: %s\n", *cfg.OptionalVal)
-	} else {
-		fmt.Printf("  Optional Value: <not set>\n")
-	}
+	// Redacted masks sensitive and secret fields, such as APIKey. With pkg/log:
+	// log.InfoFields("Configuration loaded", env.Redacted(&cfg))
+	fmt.Printf("Configuration loaded successfully: %v\n", env.Redacted(&cfg))
 
 	// You can still define OTHER flags manually if needed, just don't
 	// redefine the ones handled by the Config struct.
@@ -0,0 +1,84 @@
+package env
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// WithEnvconfig names environment variables following the conventions of
+// github.com/kelseyhightower/envconfig, for fields without an `env` tag: the prefix,
+// an underscore and the upper-cased field name (e.g., prefix "gcp" and field Port
+// read GCP_PORT), or the `envconfig` tag instead of the field name, with the
+// `envconfig` tag alone as a fallback. Fields tagged `split_words:"true"` separate
+// the words of their name with underscores. Nested structs extend the prefix with
+// their own name. `default`, `required` and `ignored` tags work as in envconfig.
+func WithEnvconfig() Option {
+	return func(l *Loader) { l.envconfig = true }
+}
+
+// WithDotenvDisabled does not read .env files.
+func WithDotenvDisabled() Option {
+	return func(l *Loader) { l.dotenvDisabled = true }
+}
+
+// NewEnvconfigLoader returns a loader in envconfig mode (WithEnvconfig) that, like
+// envconfig.Process, only reads environment variables and defaults: it defines no
+// command-line flags on flag.CommandLine and reads no .env files. Options may add
+// sources back, e.g. WithConfigFiles or WithProfile.
+func NewEnvconfigLoader(opts ...Option) *Loader {
+	fs := flag.NewFlagSet("envconfig", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return NewLoader(append([]Option{
+		WithEnvconfig(),
+		WithFlagSet(fs),
+		WithArgs([]string{}),
+		WithDotenvDisabled(),
+	}, opts...)...)
+}
+
+// envconfigPrefix returns the prefix of environment variable names in envconfig mode:
+// the prefix passed to Load, separated from the key by an underscore.
+func envconfigPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "_") {
+		return prefix
+	}
+	return prefix + "_"
+}
+
+// envconfigKey returns the unprefixed envconfig key of a field: the `envconfig` tag,
+// else its name, split into words if it is tagged `split_words:"true"`.
+func envconfigKey(sf reflect.StructField) string {
+	if alt := sf.Tag.Get(TagEnvconfig); alt != "" {
+		return strings.ToUpper(alt)
+	}
+	if sf.Tag.Get(TagSplitWords) == "true" {
+		return strings.ToUpper(splitWords(sf.Name))
+	}
+	return strings.ToUpper(sf.Name)
+}
+
+var (
+	// wordRegexp and acronymRegexp split names into words as envconfig does.
+	wordRegexp    = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+// splitWords separates the words of a Go name with underscores, e.g. "AutoTLSConfig"
+// becomes "Auto_TLS_Config".
+func splitWords(name string) string {
+	var words []string
+	for _, word := range wordRegexp.FindAllString(name, -1) {
+		if m := acronymRegexp.FindStringSubmatch(word); len(m) == 3 {
+			words = append(words, m[1], m[2])
+		} else {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return name
+	}
+	return strings.Join(words, "_")
+}
@@ -0,0 +1,95 @@
+package env
+
+import (
+	"context"
+	"flag"
+	"testing"
+	"time"
+)
+
+type envconfigTestConfig struct {
+	Host       string        `default:"0.0.0.0"`
+	Port       int           `default:"8080"`
+	MaxRetries int           `split_words:"true"`
+	Region     string        `envconfig:"CLOUD_REGION"`
+	Timeout    time.Duration `env:"CUSTOM_TIMEOUT"`
+	Skipped    string        `ignored:"true"`
+	DB         struct {
+		User string
+	}
+	Embedded
+}
+
+type Embedded struct {
+	Zone string
+}
+
+// TestEnvconfigNaming tests that envconfig mode names variables like envconfig.Process.
+func TestEnvconfigNaming(t *testing.T) {
+	env := map[string]string{
+		"GCP_PORT":           "9090",
+		"GCP_MAX_RETRIES":    "5",
+		"CLOUD_REGION":       "us-east1",
+		"GCP_CUSTOM_TIMEOUT": "3s",
+		"GCP_SKIPPED":        "nope",
+		"GCP_DB_USER":        "admin",
+		"GCP_ZONE":           "b",
+	}
+	l := newTestLoader(nil, env, WithEnvconfig())
+
+	var cfg envconfigTestConfig
+	if err := l.Load(context.Background(), "gcp", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Host != "0.0.0.0" || cfg.Port != 9090 || cfg.MaxRetries != 5 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if cfg.Region != "us-east1" {
+		t.Errorf("Expected the unprefixed envconfig tag fallback, got %q", cfg.Region)
+	}
+	if cfg.Timeout != 3*time.Second || cfg.Skipped != "" || cfg.DB.User != "admin" || cfg.Zone != "b" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+
+	// The prefixed envconfig tag takes precedence over the unprefixed one.
+	env["GCP_CLOUD_REGION"] = "europe-west1"
+	if err := l.Load(context.Background(), "gcp", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Region != "europe-west1" {
+		t.Errorf("Expected the prefixed envconfig tag, got %q", cfg.Region)
+	}
+}
+
+// TestSplitWords tests the splitting of Go names into words.
+func TestSplitWords(t *testing.T) {
+	tests := map[string]string{
+		"Port":          "Port",
+		"MaxRetries":    "Max_Retries",
+		"AutoTLSConfig": "Auto_TLS_Config",
+		"HTTPPort":      "HTTP_Port",
+	}
+	for name, want := range tests {
+		if got := splitWords(name); got != want {
+			t.Errorf("Expected %q for %q, got %q", want, name, got)
+		}
+	}
+}
+
+// TestNewEnvconfigLoader tests that envconfig loaders define no flags on the command line
+// and apply profile defaults.
+func TestNewEnvconfigLoader(t *testing.T) {
+	var cfg struct {
+		Replicas int `default:"1" default.prod:"3"`
+	}
+	l := NewEnvconfigLoader(WithLookupEnv(mapEnv(nil)), WithProfile("prod"))
+	if err := l.Load(context.Background(), "app", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Replicas != 3 {
+		t.Errorf("Expected the profile default 3, got %d", cfg.Replicas)
+	}
+	if f := flag.CommandLine.Lookup("replicas"); f != nil {
+		t.Errorf("Expected no flag on flag.CommandLine")
+	}
+}
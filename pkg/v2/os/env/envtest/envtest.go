@@ -0,0 +1,189 @@
+// Package envtest loads v2 env config specs against hermetic fixtures and compares
+// the results with golden files, so that config structs can be tested without
+// touching os.Setenv, flag.CommandLine or real secret stores.
+//
+//	func TestConfig(t *testing.T) {
+//		fixture := envtest.Fixture{
+//			Env:     map[string]string{"APP_PORT": "9090"},
+//			Args:    []string{"--debug"},
+//			Dotenv:  "APP_HOST=example.com\n",
+//			Secrets: map[string]string{"db-password": "s3cret"},
+//		}
+//		var cfg Config
+//		report, err := fixture.Load(t, "APP_", &cfg)
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		envtest.AssertGolden(t, "config", &cfg, report)
+//	}
+//
+// Golden files are read from testdata/<name>.golden; run the tests with -update-golden
+// to write them.
+package envtest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/finiteloopme/goutils/pkg/v2/os/env"
+)
+
+var update = flag.Bool("update-golden", false, "Write envtest golden files instead of comparing them")
+
+// Fixture describes the environment of a load. Nothing outside of it is read:
+// the process environment, command line and files of the working directory are ignored.
+type Fixture struct {
+	// Env holds the environment variables.
+	Env map[string]string
+	// Args holds the command-line arguments, without the program name.
+	Args []string
+	// Dotenv holds the contents of the .env file, if any.
+	Dotenv string
+	// Files maps config file names (e.g., "config.yaml") to their contents.
+	// They are loaded in order of name.
+	Files map[string]string
+	// Secrets maps secret names, without URI scheme, to their values. They back the
+	// default secret provider and the gcp:// and file:// schemes.
+	Secrets map[string]string
+	// Profile is the active profile. If empty, it is read from APP_PROFILE in Env or Dotenv.
+	Profile string
+	// Options configure the loader further, e.g. env.WithExpansion().
+	Options []env.Option
+}
+
+// Loader returns a loader reading only from the fixture. Files are written to a
+// temporary directory removed when t ends.
+func (f Fixture) Loader(t testing.TB) *env.Loader {
+	t.Helper()
+	dir := t.TempDir()
+
+	fs := flag.NewFlagSet(t.Name(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	args := f.Args
+	if args == nil {
+		args = []string{}
+	}
+	secrets := env.NewMemorySecretProvider(f.Secrets)
+	opts := []env.Option{
+		env.WithFlagSet(fs),
+		env.WithArgs(args),
+		env.WithLookupEnv(func(key string) (string, bool) {
+			value, ok := f.Env[key]
+			return value, ok
+		}),
+		env.WithDotenvIsolated(),
+		env.WithDotenvPaths(writeFile(t, dir, ".env", f.Dotenv, f.Dotenv != "")),
+		env.WithSecretProvider(secrets),
+		env.WithSecretScheme(env.SecretSchemeGCP, secrets),
+		env.WithSecretScheme(env.SecretSchemeFile, secrets),
+		env.WithProfile(f.Profile),
+	}
+
+	names := make([]string, 0, len(f.Files))
+	for name := range f.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = writeFile(t, dir, name, f.Files[name], true)
+	}
+	if len(paths) > 0 {
+		opts = append(opts, env.WithConfigFiles(paths...))
+	}
+	return env.NewLoader(append(opts, f.Options...)...)
+}
+
+// Load loads spec with the fixture's loader, see Loader.
+func (f Fixture) Load(t testing.TB, prefix string, spec interface{}) (*env.Report, error) {
+	t.Helper()
+	return f.Loader(t).LoadWithReport(context.Background(), prefix, spec)
+}
+
+// writeFile writes contents to name in dir if write is set, and returns its path.
+func writeFile(t testing.TB, dir, name, contents string, write bool) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if !write {
+		return path
+	}
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("envtest: writing %s: %v", name, err)
+	}
+	return path
+}
+
+// Snapshot renders the fields of spec as indented JSON keyed by their dotted path, followed
+// by the report, if any, as a table. Values of sensitive fields are redacted in both.
+func Snapshot(spec interface{}, report *env.Report) (string, error) {
+	data, err := json.MarshalIndent(env.Redacted(spec), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("# spec\n")
+	sb.Write(data)
+	sb.WriteString("\n")
+	if report != nil {
+		sb.WriteString("\n# report\n")
+		sb.WriteString(report.String())
+	}
+	// Drop the padding of the report's table, which editors tend to strip from golden files.
+	lines := strings.Split(sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// AssertGolden compares the snapshot of spec and report with testdata/<name>.golden,
+// and reports a mismatch as a test error. With -update-golden, it writes the golden file instead.
+func AssertGolden(t testing.TB, name string, spec interface{}, report *env.Report) {
+	t.Helper()
+	got, err := Snapshot(spec, report)
+	if err != nil {
+		t.Fatalf("envtest: snapshot of %s: %v", name, err)
+	}
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("envtest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("envtest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("envtest: reading golden file (run with -update-golden to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("envtest: %s does not match (run with -update-golden to update it):\n%s", path, diff(string(want), got))
+	}
+}
+
+// diff returns the lines of want and got from the first one that differs, marked with - and +.
+func diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	i := 0
+	for i < len(wantLines) && i < len(gotLines) && wantLines[i] == gotLines[i] {
+		i++
+	}
+	var sb strings.Builder
+	for _, line := range wantLines[i:] {
+		sb.WriteString("- " + line + "\n")
+	}
+	for _, line := range gotLines[i:] {
+		sb.WriteString("+ " + line + "\n")
+	}
+	return sb.String()
+}
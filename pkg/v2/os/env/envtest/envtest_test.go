@@ -0,0 +1,63 @@
+package envtest
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/finiteloopme/goutils/pkg/v2/os/env"
+)
+
+type testConfig struct {
+	Host     string        `env:"HOST" default:"localhost"`
+	Port     int           `env:"PORT" flag:"port" default:"8080"`
+	Timeout  time.Duration `file:"timeout" default:"5s"`
+	Debug    bool          `flag:"debug"`
+	Replicas int           `env:"REPLICAS" default:"1" default.prod:"3"`
+	Password string        `env:"PASSWORD" secret:"db-password"`
+}
+
+// TestFixtureGolden tests loading every source of a fixture against a golden file.
+func TestFixtureGolden(t *testing.T) {
+	fixture := Fixture{
+		Env:     map[string]string{"APP_PORT": "9090", "APP_PROFILE": "prod"},
+		Args:    []string{"--debug", "--port=7070"},
+		Dotenv:  "APP_HOST=example.com\n",
+		Files:   map[string]string{"config.yaml": "timeout: 30s\n"},
+		Secrets: map[string]string{"db-password": "s3cret"},
+	}
+	var cfg testConfig
+	report, err := fixture.Load(t, "APP_", &cfg)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	AssertGolden(t, "fixture", &cfg, report)
+}
+
+// TestFixtureHermetic tests that the process environment is neither read nor written.
+func TestFixtureHermetic(t *testing.T) {
+	t.Setenv("APP_HOST", "from-process")
+	fixture := Fixture{Dotenv: "APP_PORT=1234\n", Secrets: map[string]string{"db-password": ""}}
+	var cfg testConfig
+	if _, err := fixture.Load(t, "APP_", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Host != "localhost" || cfg.Port != 1234 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if _, ok := os.LookupEnv("APP_PORT"); ok {
+		t.Errorf("Expected the .env value not to be set in the process environment")
+	}
+	if port, _ := env.String("APP_PORT", env.FromLoader(fixture.Loader(t))); port != "1234" {
+		t.Errorf("Expected the fixture's .env value, got %q", port)
+	}
+}
+
+// TestSnapshotDiff tests that mismatches show the differing lines.
+func TestSnapshotDiff(t *testing.T) {
+	got := diff("a\nb\nc", "a\nx\nc")
+	if !strings.Contains(got, "- b\n") || !strings.Contains(got, "+ x\n") || strings.Contains(got, "a\n") {
+		t.Errorf("Unexpected diff:\n%s", got)
+	}
+}
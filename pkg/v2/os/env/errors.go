@@ -0,0 +1,141 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Kinds of field errors, matched with errors.Is on a ConfigError or a FieldError.
+var (
+	// ErrRequired indicates that a required field has no value.
+	ErrRequired = errors.New("required value missing")
+	// ErrParse indicates that a raw value or config file could not be parsed.
+	ErrParse = errors.New("invalid value")
+	// ErrSecretAccess indicates that a secret could not be fetched from its provider.
+	ErrSecretAccess = errors.New("secret access failed")
+	// ErrUnsupportedType indicates that a field's type cannot be loaded from a source.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrValidation indicates that a value violates a constraint tag or the spec's Validate method.
+	ErrValidation = errors.New("validation failed")
+)
+
+// FieldError describes why a field could not be loaded.
+type FieldError struct {
+	// Field is the dotted Go path of the field; empty for errors not tied to a field,
+	// such as unreadable config files or a failing Validate method.
+	Field string
+	// Source is the source the value came from, if any (e.g., SourceEnvironment).
+	Source string
+	// Key is the name the value was looked up under in Source.
+	Key string
+	// RawValue is the raw value that failed, if any, or RedactedValue for sensitive fields.
+	RawValue string
+	// Kind is one of the Err* sentinels.
+	Kind error
+	// Err is the underlying error, if any.
+	Err error
+
+	// msg is the human-readable message.
+	msg string
+}
+
+func (e *FieldError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	msg := e.Kind.Error()
+	if e.Field != "" {
+		msg = fmt.Sprintf("field %q: %s", e.Field, msg)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the kind and the underlying error, so errors.Is matches both.
+func (e *FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// ConfigError collects all the errors of a Load.
+// Use errors.As to retrieve it, or errors.Is to test for a kind of error.
+type ConfigError struct {
+	Errors []FieldError
+
+	// title introduces the list of errors in the message.
+	title string
+}
+
+func (e *ConfigError) Error() string {
+	title := e.title
+	if title == "" {
+		title = "config loading errors"
+	}
+	msgs := make([]string, len(e.Errors))
+	for i := range e.Errors {
+		msgs[i] = e.Errors[i].Error()
+	}
+	return fmt.Sprintf("%s:\n - %s", title, strings.Join(msgs, "\n - "))
+}
+
+// Unwrap returns the field errors.
+func (e *ConfigError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i := range e.Errors {
+		errs[i] = &e.Errors[i]
+	}
+	return errs
+}
+
+// newConfigError returns a ConfigError for errs, or nil if there are none.
+func newConfigError(title string, errs []FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ConfigError{Errors: errs, title: title}
+}
+
+// rawValue returns value for FieldError.RawValue, redacted if fs is sensitive.
+func rawValue(fs fieldSpec, value string) string {
+	if isSensitive(fs) {
+		return RedactedValue
+	}
+	return value
+}
+
+// redactedCause returns err for FieldError.Err and messages, or, if fs is sensitive,
+// an error saying what failed without the text of err, which may quote the value
+// (e.g., strconv errors, or expansion errors quoting the whole value).
+func redactedCause(fs fieldSpec, err error, what string) error {
+	if err == nil || !isSensitive(fs) {
+		return err
+	}
+	return fmt.Errorf("cannot %s %s value %s", what, fs.Value.Type(), RedactedValue)
+}
+
+// unsupportedTypeError is returned by setFieldValue for kinds it cannot set.
+type unsupportedTypeError struct {
+	kind reflect.Kind
+}
+
+func (e unsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported field type %s", e.kind)
+}
+
+func (e unsupportedTypeError) Is(target error) bool {
+	return target == ErrUnsupportedType
+}
+
+// errorKind returns the kind of an error returned by setFieldValue.
+func errorKind(err error) error {
+	if errors.Is(err, ErrUnsupportedType) {
+		return ErrUnsupportedType
+	}
+	return ErrParse
+}
@@ -0,0 +1,121 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestConfigError tests that loading failures are reported as a ConfigError
+// whose field errors match their kind with errors.Is.
+func TestConfigError(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"PORT": "eighty", "PASSWORD": "ignored"})
+
+	var cfg struct {
+		Host     string `env:"HOST" required:"true"`
+		Port     int    `env:"PORT"`
+		Password string `secret:"db-password"`
+		Level    string `env:"LEVEL" default:"trace" oneof:"debug info"`
+	}
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if !strings.HasPrefix(err.Error(), "config loading errors:\n - ") {
+		t.Errorf("Expected the aggregated message format, got:\n%s", err)
+	}
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("Expected a *ConfigError, got %T", err)
+	}
+	if len(configErr.Errors) != 4 {
+		t.Fatalf("Expected 4 field errors, got %d: %v", len(configErr.Errors), err)
+	}
+	for _, kind := range []error{ErrRequired, ErrParse, ErrSecretAccess, ErrValidation, ErrSecretNotFound} {
+		if !errors.Is(err, kind) {
+			t.Errorf("Expected errors.Is(err, %v) to be true", kind)
+		}
+	}
+	if errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected errors.Is(err, ErrUnsupportedType) to be false")
+	}
+
+	byField := make(map[string]FieldError)
+	for _, fe := range configErr.Errors {
+		byField[fe.Field] = fe
+	}
+	if fe := byField["Port"]; fe.Kind != ErrParse || fe.Source != SourceEnvironment || fe.Key != "PORT" || fe.RawValue != "eighty" {
+		t.Errorf("Unexpected Port error: %+v", fe)
+	}
+	if fe := byField["Host"]; fe.Kind != ErrRequired {
+		t.Errorf("Expected Host error kind ErrRequired, got %v", fe.Kind)
+	}
+	if fe := byField["Password"]; fe.Kind != ErrSecretAccess || fe.Key != "db-password" {
+		t.Errorf("Unexpected Password error: %+v", fe)
+	}
+	if fe := byField["Level"]; fe.Kind != ErrValidation || fe.Source != SourceDefault || fe.RawValue != "trace" {
+		t.Errorf("Unexpected Level error: %+v", fe)
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field == "" {
+		t.Errorf("Expected errors.As to find a *FieldError, got %+v", fieldErr)
+	}
+}
+
+// TestConfigErrorUnsupportedType tests that flags of unsupported types are reported as ErrUnsupportedType.
+func TestConfigErrorUnsupportedType(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, nil)
+
+	var cfg struct {
+		Handler func() `flag:"handler"`
+	}
+	err := l.Load(context.Background(), "", &cfg)
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("Expected ErrUnsupportedType, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "flag definition errors:\n - ") {
+		t.Errorf("Expected the flag definition message format, got:\n%s", err)
+	}
+}
+
+// TestConfigErrorRedactsSensitiveValues tests that no error message quotes the value of a sensitive field.
+func TestConfigErrorRedactsSensitiveValues(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(
+		[]string{"--pin=flag-SECRET-4"},
+		map[string]string{"TOKEN": "tok-SECRET-2"},
+		WithSecretProvider(NewMemorySecretProvider(map[string]string{
+			"db-port":     "hunter2-SECRET",
+			"db-password": "${unterminated-SECRET-3",
+		})),
+	)
+
+	var cfg struct {
+		Port     int    `secret:"db-port"`
+		Token    int    `env:"TOKEN" sensitive:"true"`
+		Password string `secret:"db-password" expand:"true"`
+		Pin      int    `flag:"pin" sensitive:"true"`
+		Limit    int    `env:"LIMIT" default:"default-SECRET-5" sensitive:"true" required:"true"`
+	}
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil {
+		t.Fatalf("Expected errors, got nil")
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "SECRET") {
+		t.Errorf("Expected sensitive values to be redacted, got:\n%s", msg)
+	}
+	for _, field := range []string{"Port", "Token", "Password", "Pin", "Limit"} {
+		if !strings.Contains(msg, `field "`+field+`"`) {
+			t.Errorf("Expected an error for %s, got:\n%s", field, msg)
+		}
+	}
+	if !errors.Is(err, ErrParse) {
+		t.Errorf("Expected ErrParse, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rawField is the raw value a field got from its sources, before it is set.
+type rawField struct {
+	fs       fieldSpec
+	value    string
+	found    bool
+	source   string
+	provided []SourceValue
+
+	// expand is set if ${...} references in value are expanded.
+	expand bool
+}
+
+// expansionEnabled reports whether the raw value of a field is expanded: fields tagged
+// `expand:"true"`, or all fields not tagged `expand:"false"` if the loader has WithExpansion.
+func (l *Loader) expansionEnabled(fs fieldSpec) bool {
+	switch fs.Field.Tag.Get(TagExpand) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return l.expand
+}
+
+// expandsValue reports whether a raw value of fs loaded from source is expanded. Values
+// from secrets are opaque (a password may hold "$$" or "${"), so WithExpansion leaves
+// them as-is; they are only expanded in fields tagged `expand:"true"`.
+func (l *Loader) expandsValue(fs fieldSpec, source string) bool {
+	if source == SourceSecret {
+		return fs.Field.Tag.Get(TagExpand) == "true"
+	}
+	return l.expansionEnabled(fs)
+}
+
+// expander expands ${NAME} and ${NAME:-fallback} references in raw values.
+// NAME is the dotted path or the environment variable of another field of the spec,
+// else an environment variable. "$$" is an escaped "$".
+type expander struct {
+	lookupEnv func(key string) (string, bool)
+	fields    map[string]*rawField
+
+	expanded map[*rawField]string
+	visiting map[*rawField]bool
+}
+
+// newExpander returns an expander resolving references to the fields in raws.
+func newExpander(prefix string, raws []*rawField, lookupEnv func(key string) (string, bool)) *expander {
+	e := &expander{
+		lookupEnv: lookupEnv,
+		fields:    make(map[string]*rawField),
+		expanded:  make(map[*rawField]string),
+		visiting:  make(map[*rawField]bool),
+	}
+	for _, rf := range raws {
+		if rf.fs.EnvKey != "" {
+			e.fields[strings.ToUpper(prefix+rf.fs.EnvKey)] = rf
+		}
+	}
+	for _, rf := range raws {
+		e.fields[rf.fs.Name] = rf // Paths take precedence over env names
+	}
+	return e
+}
+
+// expandField expands the raw value of rf, once. Fields without expansion are returned as-is.
+// The path lists the fields being expanded, to report cycles.
+func (e *expander) expandField(rf *rawField, path []string) (string, error) {
+	if !rf.expand || !rf.found {
+		return rf.value, nil
+	}
+	if value, ok := e.expanded[rf]; ok {
+		return value, nil
+	}
+	path = append(path, rf.fs.Name)
+	if e.visiting[rf] {
+		return "", fmt.Errorf("expansion cycle: %s", strings.Join(path, " -> "))
+	}
+	e.visiting[rf] = true
+	defer delete(e.visiting, rf)
+
+	value, err := e.expand(rf, rf.value, path)
+	if err != nil {
+		return "", err
+	}
+	e.expanded[rf] = value
+	return value, nil
+}
+
+// expand expands the references in s, a value of rf.
+func (e *expander) expand(rf *rawField, s string, path []string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+			continue
+		case '{':
+		default:
+			sb.WriteByte('$')
+			continue
+		}
+
+		end := matchingBrace(s, i+1)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference in %q", s)
+		}
+		name, fallback, hasFallback := strings.Cut(s[i+2:end], ":-")
+		if name == "" {
+			return "", fmt.Errorf("empty reference in %q", s)
+		}
+		value, ok, err := e.resolve(rf, name, path)
+		if err != nil {
+			return "", err
+		}
+		if hasFallback && (!ok || value == "") {
+			if value, err = e.expand(rf, fallback, path); err != nil {
+				return "", err
+			}
+		}
+		sb.WriteString(value)
+		i = end
+	}
+	return sb.String(), nil
+}
+
+// resolve returns the value of a reference, and whether it is set.
+func (e *expander) resolve(rf *rawField, name string, path []string) (string, bool, error) {
+	if ref, ok := e.fields[name]; ok {
+		if !ref.found {
+			return "", false, nil
+		}
+		value, err := e.expandField(ref, path)
+		if err != nil {
+			return "", false, err
+		}
+		if isSensitive(ref.fs) {
+			rf.fs.Sensitive = true
+		}
+		return value, true, nil
+	}
+	value, ok := e.lookupEnv(name)
+	return value, ok, nil
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or -1.
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,178 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type expandTestConfig struct {
+	Home   string `env:"HOME_DIR"`
+	Cache  string `env:"CACHE" default:"${HOME_DIR}/.cache/app"`
+	User   string `env:"DB_USER" default:"app"`
+	Host   string `env:"DB_HOST" flag:"db-host" default:"localhost"`
+	URL    string `env:"DATABASE_URL"`
+	Region string `env:"REGION" default:"${GCP_REGION:-us-central1}"`
+	Price  string `env:"PRICE" default:"$$5 for $${HOME_DIR}"`
+	Port   int    `env:"PORT" flag:"port" default:"${BASE_PORT:-8000}"`
+	Pass   string `secret:"db-password"`
+	DSN    string `env:"DSN" default:"${User}:${Pass}@${Host}"`
+}
+
+// TestExpansion tests references to environment variables and other fields,
+// fallbacks and escapes.
+func TestExpansion(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader([]string{"--db-host", "db.internal"}, map[string]string{
+		"HOME_DIR":     "/home/app",
+		"DATABASE_URL": "postgres://${DB_USER}@${DB_HOST}/app",
+	},
+		WithExpansion(),
+		WithSecretProvider(NewMemorySecretProvider(map[string]string{"db-password": "s3cret"})),
+	)
+
+	var cfg expandTestConfig
+	report, err := l.LoadWithReport(context.Background(), "", &cfg)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := map[string]string{
+		"Cache":  "/home/app/.cache/app",
+		"URL":    "postgres://app@db.internal/app",
+		"Region": "us-central1",
+		"Price":  "$5 for ${HOME_DIR}",
+		"DSN":    "app:s3cret@db.internal",
+	}
+	got := map[string]string{"Cache": cfg.Cache, "URL": cfg.URL, "Region": cfg.Region, "Price": cfg.Price, "DSN": cfg.DSN}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("Expected %s %q, got %q", name, w, got[name])
+		}
+	}
+	if cfg.Port != 8000 {
+		t.Errorf("Expected Port 8000, got %d", cfg.Port)
+	}
+	if fr := report.Field("DSN"); fr == nil || fr.Value != RedactedValue || !fr.Sensitive {
+		t.Errorf("Expected DSN to be redacted as it includes a secret, got %+v", fr)
+	}
+}
+
+// TestExpansionOptIn tests that values are used literally unless expansion is enabled.
+func TestExpansionOptIn(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"NAME": "app"})
+
+	var cfg struct {
+		Literal  string `env:"LITERAL" default:"${NAME}"`
+		Expanded string `env:"EXPANDED" default:"${NAME}" expand:"true"`
+	}
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Literal != "${NAME}" {
+		t.Errorf("Expected Literal '${NAME}', got %q", cfg.Literal)
+	}
+	if cfg.Expanded != "app" {
+		t.Errorf("Expected Expanded 'app', got %q", cfg.Expanded)
+	}
+}
+
+// TestExpansionSecrets tests that WithExpansion leaves secret values as-is unless the field opts in.
+func TestExpansionSecrets(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"HOST": "db.internal"},
+		WithExpansion(),
+		WithSecretProvider(NewMemorySecretProvider(map[string]string{
+			"password": "pa$$word",
+			"token":    "${unterminated",
+			"dsn":      "postgres://${HOST}/app",
+		})),
+	)
+
+	var cfg struct {
+		Password string `secret:"password"`
+		Token    string `secret:"token"`
+		DSN      string `secret:"dsn" expand:"true"`
+	}
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Password != "pa$$word" {
+		t.Errorf("Expected Password 'pa$$word', got %q", cfg.Password)
+	}
+	if cfg.Token != "${unterminated" {
+		t.Errorf("Expected Token '${unterminated', got %q", cfg.Token)
+	}
+	if cfg.DSN != "postgres://db.internal/app" {
+		t.Errorf("Expected DSN 'postgres://db.internal/app', got %q", cfg.DSN)
+	}
+}
+
+// TestExpansionRedactsSensitiveValues tests that values expanded from a secret are redacted
+// in errors and reports, whichever check fails.
+func TestExpansionRedactsSensitiveValues(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, nil,
+		WithSecretProvider(NewMemorySecretProvider(map[string]string{"pw": "hunter2", "zero": "0"})),
+	)
+
+	var cfg struct {
+		Password string `secret:"pw"`
+		Zero     string `secret:"zero"`
+		DSN      string `default:"postgres//u:${Password}@h" expand:"true" url:"true"`
+		Port     int    `default:"${Password}" expand:"true"`
+		Code     int    `default:"${Zero}" expand:"true" required:"true"`
+		Broken   string `default:"${Password}${OPEN" expand:"true"`
+	}
+	report, err := l.LoadWithReport(context.Background(), "", &cfg)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("Expected a ConfigError, got %v", err)
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected expanded secrets to be redacted, got:\n%s", err)
+	}
+	failed := make(map[string]bool)
+	for _, fe := range configErr.Errors {
+		failed[fe.Field] = true
+		if fe.RawValue != RedactedValue {
+			t.Errorf("Expected redacted raw value for %s, got %q", fe.Field, fe.RawValue)
+		}
+	}
+	for _, field := range []string{"DSN", "Port", "Code", "Broken"} {
+		if !failed[field] {
+			t.Errorf("Expected an error for %s, got:\n%s", field, err)
+		}
+	}
+	for _, fr := range report.Fields {
+		if fr.Field != "Password" && fr.Field != "Zero" && (!fr.Sensitive || fr.Value != RedactedValue) {
+			t.Errorf("Expected %s to be redacted in the report, got %+v", fr.Field, fr)
+		}
+	}
+	if strings.Contains(report.String(), "hunter2") {
+		t.Errorf("Expected expanded secrets to be redacted in the report, got:\n%s", report)
+	}
+}
+
+// TestExpansionErrors tests that cycles and malformed references are reported.
+func TestExpansionErrors(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, nil, WithExpansion())
+
+	var cfg struct {
+		A      string `env:"A" default:"${B}"`
+		B      string `env:"B" default:"x${C}"`
+		C      string `env:"C" default:"${A}"`
+		Broken string `env:"BROKEN" default:"${OPEN"`
+	}
+	err := l.Load(context.Background(), "", &cfg)
+	if !errors.Is(err, ErrParse) {
+		t.Fatalf("Expected ErrParse, got %v", err)
+	}
+	for _, want := range []string{"expansion cycle: A -> B -> C -> A", `unterminated reference in "${OPEN"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package env
+
+import (
+	"encoding"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ExportOption configures ExportEnv, ExportFlags, ExportYAML and ExportJSON.
+type ExportOption func(*exportOptions)
+
+type exportOptions struct {
+	secrets bool
+}
+
+// WithSecretFields exports fields with a `secret` tag, which are skipped by default
+// so that secrets are not handed down to child processes or written to files.
+func WithSecretFields() ExportOption {
+	return func(o *exportOptions) { o.secrets = true }
+}
+
+// exportFields returns the leaf fields of spec to export, in declaration order.
+// Nil pointer fields are skipped, as are secret fields unless opts allow them.
+func exportFields(spec interface{}, opts []ExportOption) ([]fieldSpec, error) {
+	specValue := reflect.ValueOf(spec)
+	if specValue.Kind() != reflect.Ptr || specValue.IsNil() || specValue.Elem().Kind() != reflect.Struct {
+		return nil, errInvalidSpecification
+	}
+	var o exportOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var fields []fieldSpec
+	for _, fs := range collectFieldsOfCopy(specValue.Elem()) {
+		if fs.SecretName != "" && !o.secrets {
+			continue
+		}
+		if fs.Value.Kind() == reflect.Ptr && fs.Value.IsNil() {
+			continue
+		}
+		fields = append(fields, fs)
+	}
+	return fields, nil
+}
+
+// ExportEnv renders spec as a list of "NAME=value" environment entries, one per field
+// with an `env` tag, e.g. for exec.Cmd's Env. The prefix is applied as by ProcessConfig,
+// and values are formatted so that loading the entries yields the same spec.
+func ExportEnv(prefix string, spec interface{}, opts ...ExportOption) ([]string, error) {
+	fields, err := exportFields(spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	for _, fs := range fields {
+		if fs.EnvKey == "" {
+			continue
+		}
+		value := formatRawValue(fs.Value, valueOptionsFromTag(fs.Field.Tag))
+		env = append(env, strings.ToUpper(prefix+fs.EnvKey)+"="+value)
+	}
+	return env, nil
+}
+
+// ExportFlags renders spec as command-line arguments ("--name=value"), one per field
+// with a `flag` tag. Slice and map fields are rendered as one argument per element or entry.
+func ExportFlags(spec interface{}, opts ...ExportOption) ([]string, error) {
+	fields, err := exportFields(spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, fs := range fields {
+		if fs.FlagName == "" {
+			continue
+		}
+		valueOpts := valueOptionsFromTag(fs.Field.Tag)
+		if !isRepeatable(fs.Value.Type()) {
+			args = append(args, "--"+fs.FlagName+"="+formatRawValue(fs.Value, valueOpts))
+			continue
+		}
+		v := reflect.Indirect(fs.Value)
+		if v.Kind() == reflect.Map {
+			for _, entry := range formatMapEntries(v, valueOpts) {
+				args = append(args, "--"+fs.FlagName+"="+entry)
+			}
+			continue
+		}
+		for i := 0; i < v.Len(); i++ {
+			args = append(args, "--"+fs.FlagName+"="+formatRawValue(v.Index(i), valueOpts))
+		}
+	}
+	return args, nil
+}
+
+// ExportYAML writes spec as a YAML config file, keyed as it is read by the loader.
+func ExportYAML(w io.Writer, spec interface{}, opts ...ExportOption) error {
+	values, err := exportConfig(spec, opts)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ExportJSON writes spec as a JSON config file, keyed as it is read by the loader.
+func ExportJSON(w io.Writer, spec interface{}, opts ...ExportOption) error {
+	values, err := exportConfig(spec, opts)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// exportConfig returns the nested config file values of the fields of spec with a file key.
+func exportConfig(spec interface{}, opts []ExportOption) (map[string]interface{}, error) {
+	fields, err := exportFields(spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	for _, fs := range fields {
+		if fs.FileKey == nil {
+			continue
+		}
+		m := values
+		for _, key := range fs.FileKey[:len(fs.FileKey)-1] {
+			nested, ok := m[key].(map[string]interface{})
+			if !ok {
+				nested = make(map[string]interface{})
+				m[key] = nested
+			}
+			m = nested
+		}
+		m[fs.FileKey[len(fs.FileKey)-1]] = configFileValue(fs.Value, valueOptionsFromTag(fs.Field.Tag))
+	}
+	return values, nil
+}
+
+// formatRawValue formats v in the raw string form accepted by setFieldValue.
+// Slices and maps are joined with the separators of opts.
+func formatRawValue(v reflect.Value, opts valueOptions) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if hasCustomDecoder(v.Type()) {
+		return formatCustom(v)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			return time.Duration(v.Int()).String()
+		}
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatRawValue(v.Index(i), opts)
+		}
+		return strings.Join(parts, opts.separator)
+	case reflect.Map:
+		return strings.Join(formatMapEntries(v, opts), opts.separator)
+	}
+	return formatValue(v)
+}
+
+// formatMapEntries formats the entries of the map v as "key<kvSeparator>value", sorted by key.
+func formatMapEntries(v reflect.Value, opts valueOptions) []string {
+	entries := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries = append(entries, formatRawValue(iter.Key(), opts)+opts.kvSeparator+formatRawValue(iter.Value(), opts))
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// formatCustom formats a value of a type with a custom decoder with its encoding.TextMarshaler
+// or fmt.Stringer implementation, if any.
+func formatCustom(v reflect.Value) string {
+	if !v.CanAddr() {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+	if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return formatValue(v)
+}
+
+// configFileValue converts v into a YAML or JSON value that the loader reads back into v:
+// scalars keep their type, slices and maps are nested, and other values are strings.
+func configFileValue(v reflect.Value, opts valueOptions) interface{} {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if hasCustomDecoder(v.Type()) || v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return formatRawValue(v, opts)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		// Round-trip float32 values through their shortest representation, e.g. 0.1 rather than 0.10000000149.
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), 64)
+		return f
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		elems := make([]interface{}, v.Len())
+		for i := range elems {
+			elems[i] = configFileValue(v.Index(i), opts)
+		}
+		return elems
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[formatRawValue(iter.Key(), opts)] = configFileValue(iter.Value(), opts)
+		}
+		return m
+	}
+	return formatRawValue(v, opts)
+}
@@ -0,0 +1,127 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type exportTestConfig struct {
+	Host    string            `env:"HOST" flag:"host" default:"localhost"`
+	Port    int               `env:"PORT" flag:"port" default:"8080"`
+	Debug   bool              `env:"DEBUG" flag:"debug" default:"true"`
+	Ratio   float32           `env:"RATIO" flag:"ratio"`
+	Timeout time.Duration     `env:"TIMEOUT" flag:"timeout"`
+	Tags    []string          `env:"TAGS" flag:"tag"`
+	Labels  map[string]string `env:"LABELS" flag:"label"`
+	IP      net.IP            `env:"IP" flag:"ip"`
+	Level   *int              `env:"LEVEL" flag:"level"`
+	DB      struct {
+		User     string `env:"USER" flag:"user"`
+		Password string `env:"PASSWORD" flag:"password" secret:"password"`
+	} `prefix:"DB_"`
+}
+
+// noSecrets resolves the secret of exportTestConfig to an empty value.
+var noSecrets = WithSecretProvider(NewMemorySecretProvider(map[string]string{"db-password": ""}))
+
+func newExportTestConfig() *exportTestConfig {
+	level := 3
+	cfg := &exportTestConfig{
+		Host:    "example.com",
+		Port:    9090,
+		Ratio:   0.1,
+		Timeout: 90 * time.Second,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core", "env": "prod"},
+		IP:      net.ParseIP("10.0.0.1"),
+		Level:   &level,
+	}
+	cfg.DB.User = "admin"
+	cfg.DB.Password = "s3cret"
+	return cfg
+}
+
+// TestExportEnv tests that exported env entries load back into the same spec, without secrets.
+func TestExportEnv(t *testing.T) {
+	cfg := newExportTestConfig()
+	entries, err := ExportEnv("APP_", cfg)
+	if err != nil {
+		t.Fatalf("ExportEnv failed: %v", err)
+	}
+	env := make(map[string]string)
+	for _, entry := range entries {
+		key, value, _ := strings.Cut(entry, "=")
+		env[key] = value
+	}
+	if env["APP_LABELS"] != "env:prod,team:core" || env["APP_TIMEOUT"] != "1m30s" {
+		t.Errorf("Unexpected env entries: %v", entries)
+	}
+	if _, ok := env["APP_DB_PASSWORD"]; ok {
+		t.Errorf("Expected the secret field to be skipped, got %v", entries)
+	}
+
+	var loaded exportTestConfig
+	if err := newTestLoader(nil, env, noSecrets).Load(context.Background(), "APP_", &loaded); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	cfg.DB.Password = ""
+	if !reflect.DeepEqual(&loaded, cfg) {
+		t.Errorf("Expected %+v, got %+v", *cfg, loaded)
+	}
+}
+
+// TestExportFlags tests that exported flags parse back into the same spec, with secrets if allowed.
+func TestExportFlags(t *testing.T) {
+	cfg := newExportTestConfig()
+	args, err := ExportFlags(cfg, WithSecretFields())
+	if err != nil {
+		t.Fatalf("ExportFlags failed: %v", err)
+	}
+	want := []string{"--tag=a", "--tag=b", "--label=env:prod", "--label=team:core", "--db.password=s3cret"}
+	for _, arg := range want {
+		if !containsString(args, arg) {
+			t.Errorf("Expected argument %q, got %v", arg, args)
+		}
+	}
+
+	var loaded exportTestConfig
+	secrets := NewMemorySecretProvider(map[string]string{"db-password": "s3cret"})
+	if err := newTestLoader(args, nil, WithSecretProvider(secrets)).Load(context.Background(), "", &loaded); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(&loaded, cfg) {
+		t.Errorf("Expected %+v, got %+v", *cfg, loaded)
+	}
+}
+
+// TestExportConfigFiles tests that exported YAML and JSON files load back into the same spec.
+func TestExportConfigFiles(t *testing.T) {
+	cfg := newExportTestConfig()
+	cfg.DB.Password = ""
+	for ext, export := range map[string]func(*bytes.Buffer) error{
+		".yaml": func(buf *bytes.Buffer) error { return ExportYAML(buf, cfg) },
+		".json": func(buf *bytes.Buffer) error { return ExportJSON(buf, cfg) },
+	} {
+		var buf bytes.Buffer
+		if err := export(&buf); err != nil {
+			t.Fatalf("%s: export failed: %v", ext, err)
+		}
+		if strings.Contains(buf.String(), "password") {
+			t.Errorf("%s: Expected the secret field to be skipped, got:\n%s", ext, buf.String())
+		}
+		path := writeTestFile(t, t.TempDir(), "config"+ext, buf.String())
+
+		var loaded exportTestConfig
+		if err := newTestLoader(nil, nil, WithConfigFiles(path), noSecrets).Load(context.Background(), "", &loaded); err != nil {
+			t.Fatalf("%s: Load failed: %v", ext, err)
+		}
+		if !reflect.DeepEqual(&loaded, cfg) {
+			t.Errorf("%s: Expected %+v, got %+v", ext, *cfg, loaded)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+)
+
+// fieldSpec describes a single settable leaf field of a specification struct.
+// Env, flag and secret names are already composed from the `prefix` tags of any
+// enclosing structs; the prefix passed to ProcessConfig is applied at lookup time.
+type fieldSpec struct {
+	// Name is the dotted Go path of the field, e.g. "DB.Host".
+	Name       string
+	Value      reflect.Value
+	Field      reflect.StructField
+	EnvKey     string
+	FlagName   string
+	SecretName string
+	// EnvAliases and FlagAliases are the names following the canonical one in a
+	// comma separated `env` or `flag` tag, e.g. SERVER_PORT in `env:"HTTP_PORT,SERVER_PORT"`.
+	EnvAliases  []string
+	FlagAliases []string
+	// EnvAlt is the unprefixed environment variable looked up when EnvKey is not set,
+	// from the `envconfig` tag in envconfig mode.
+	EnvAlt string
+	// FileKey is the path of keys locating the field in a config file, e.g. ["db", "host"].
+	FileKey []string
+	// Sensitive is set by the loader when the expanded value of the field includes the
+	// value of a sensitive field, e.g. `default:"postgres://u:${PASSWORD}@h"`.
+	Sensitive bool
+}
+
+// namePrefix holds the name prefixes accumulated while descending into nested structs.
+type namePrefix struct {
+	path, env, flag, secret string
+	file                    []string
+	// noFile is set below a struct excluded from config files with `file:"-"`.
+	noFile bool
+	// envconfig names environment variables like github.com/kelseyhightower/envconfig.
+	envconfig bool
+}
+
+// nest returns the prefixes to use for the fields of the nested struct field sf.
+//
+// A `prefix:"DB_"` tag extends the env prefix verbatim ("DB_" + "HOST"), the flag
+// prefix as a lower-case, dot separated segment ("db." + "host") and the secret
+// prefix as a lower-case, dash separated segment ("db-" + "password").
+// In envconfig mode, the env prefix is extended with the envconfig key of the
+// struct field instead ("DB_" for a field named DB).
+func (p namePrefix) nest(sf reflect.StructField) namePrefix {
+	n := p
+	if !sf.Anonymous {
+		n.path += sf.Name + "."
+		key := fileKey(sf)
+		n.file = append(append([]string(nil), p.file...), key)
+		n.noFile = p.noFile || key == "-"
+		if p.envconfig {
+			n.env += envconfigKey(sf) + "_"
+		}
+	}
+	if prefix := sf.Tag.Get(TagPrefix); prefix != "" {
+		base := strings.ReplaceAll(strings.ToLower(strings.Trim(prefix, "_-.")), "_", "-")
+		if !p.envconfig {
+			n.env += prefix
+		}
+		n.flag += base + "."
+		n.secret += base + "-"
+	}
+	return n
+}
+
+// secretName composes the secret name for a field. Only short secret names are
+// prefixed; fully qualified resource names (containing a "/") are used as-is.
+func (p namePrefix) secretName(name string) string {
+	if name == "" || strings.Contains(name, "/") {
+		return name
+	}
+	return p.secret + name
+}
+
+// isNestedStruct reports whether the field should be walked recursively rather
+// than set as a single value. Struct fields (or pointers to structs) that carry
+// any source tag of their own, or that have a custom decoder, are treated as leaf values.
+func isNestedStruct(sf reflect.StructField) bool {
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || hasCustomDecoder(t) {
+		return false
+	}
+	for _, tag := range []string{TagEnv, TagFlag, TagSecret, TagDefault} {
+		if _, ok := sf.Tag.Lookup(tag); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// collectFieldsOfCopy returns the leaf fields of a copy of the struct value v, so that
+// allocating nil struct pointers leaves v untouched. Values read through the fields are
+// those of v, but setting them does not change v.
+func collectFieldsOfCopy(v reflect.Value) []fieldSpec {
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	return collectFields(copied, namePrefix{})
+}
+
+// collectFields walks the struct value v and returns all of its settable leaf
+// fields, descending into nested structs and pointers to structs.
+// Nil pointers to nested structs are allocated so their fields can be populated.
+func collectFields(v reflect.Value, p namePrefix) []fieldSpec {
+	t := v.Type()
+	var fields []fieldSpec
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		fieldType := t.Field(i)
+
+		// Skip unexported fields or ignored fields
+		if !field.CanSet() || fieldType.Tag.Get(TagIgnored) == "true" {
+			continue
+		}
+
+		if isNestedStruct(fieldType) {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					field.Set(reflect.New(fieldType.Type.Elem()))
+				}
+				field = field.Elem()
+			}
+			fields = append(fields, collectFields(field, p.nest(fieldType))...)
+			continue
+		}
+
+		fs := fieldSpec{
+			Name:       p.path + fieldType.Name,
+			Value:      field,
+			Field:      fieldType,
+			SecretName: p.secretName(fieldType.Tag.Get(TagSecret)),
+		}
+		if envKey, aliases := splitNames(fieldType.Tag.Get(TagEnv), p.env); envKey != "" {
+			fs.EnvKey, fs.EnvAliases = envKey, aliases
+		} else if p.envconfig {
+			fs.EnvKey = p.env + envconfigKey(fieldType)
+			fs.EnvAlt = strings.ToUpper(fieldType.Tag.Get(TagEnvconfig))
+		}
+		fs.FlagName, fs.FlagAliases = splitNames(fieldType.Tag.Get(TagFlag), p.flag)
+		if key := fileKey(fieldType); key != "-" && !p.noFile {
+			fs.FileKey = append(append([]string(nil), p.file...), key)
+		}
+		fields = append(fields, fs)
+	}
+	return fields
+}
+
+// splitNames splits a comma separated `env` or `flag` tag into its canonical name
+// and aliases, each prefixed with prefix. Empty names are dropped.
+func splitNames(tag, prefix string) (string, []string) {
+	var names []string
+	for _, name := range strings.Split(tag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, prefix+name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return "", nil
+	case 1:
+		return names[0], nil
+	}
+	return names[0], names[1:]
+}
+
+// fileKey returns the config file key of a field: the `file` tag, else the name in
+// the `yaml` or `json` tag, else the lower-cased field name. "-" excludes the field.
+func fileKey(sf reflect.StructField) string {
+	if key := sf.Tag.Get(TagFile); key != "" {
+		return key
+	}
+	for _, tag := range []string{"yaml", "json"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" {
+			return name
+		}
+	}
+	return strings.ToLower(sf.Name)
+}
@@ -0,0 +1,96 @@
+package env
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type testDBConfig struct {
+	Host     string `env:"HOST" flag:"host" default:"localhost"`
+	Port     int    `env:"PORT" default:"5432"`
+	Password string `secret:"password"`
+	Replica  *struct {
+		Host string `env:"HOST" flag:"host"`
+	} `prefix:"REPLICA_"`
+}
+
+type testNestedConfig struct {
+	Name string       `env:"NAME"`
+	DB   testDBConfig `prefix:"DB_"`
+	// No prefix: fields are promoted into the parent namespace
+	Server struct {
+		Port int `env:"SERVER_PORT" default:"8080"`
+	}
+}
+
+// TestCollectFieldsNames tests that nested struct names are composed from prefix tags.
+func TestCollectFieldsNames(t *testing.T) {
+	var cfg testNestedConfig
+	fields := collectFields(reflect.ValueOf(&cfg).Elem(), namePrefix{})
+
+	type names struct{ env, flag, secret string }
+	want := map[string]names{
+		"Name":            {"NAME", "", ""},
+		"DB.Host":         {"DB_HOST", "db.host", ""},
+		"DB.Port":         {"DB_PORT", "", ""},
+		"DB.Password":     {"", "", "db-password"},
+		"DB.Replica.Host": {"DB_REPLICA_HOST", "db.replica.host", ""},
+		"Server.Port":     {"SERVER_PORT", "", ""},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("collectFields returned %d fields; want %d", len(fields), len(want))
+	}
+	for _, fs := range fields {
+		w, ok := want[fs.Name]
+		if !ok {
+			t.Errorf("unexpected field %q", fs.Name)
+			continue
+		}
+		got := names{fs.EnvKey, fs.FlagName, fs.SecretName}
+		if got != w {
+			t.Errorf("field %q: got names %+v; want %+v", fs.Name, got, w)
+		}
+	}
+	if cfg.DB.Replica == nil {
+		t.Errorf("expected nil nested struct pointer to be allocated")
+	}
+}
+
+// TestSecretNameQualified tests that fully qualified secret names are not prefixed.
+func TestSecretNameQualified(t *testing.T) {
+	p := namePrefix{secret: "db-"}
+	name := "projects/p/secrets/s/versions/1"
+	if got := p.secretName(name); got != name {
+		t.Errorf("secretName(%q) = %q; want unchanged", name, got)
+	}
+}
+
+// TestProcessConfigNested tests loading nested structs from defaults and the environment.
+func TestProcessConfigNested(t *testing.T) {
+	t.Setenv("NESTED_DB_HOST", "db.internal")
+	t.Setenv("NESTED_DB_REPLICA_HOST", "replica.internal")
+
+	type config struct {
+		DB struct {
+			Host    string `env:"HOST" default:"localhost"`
+			Port    int    `env:"PORT" default:"5432"`
+			Replica *struct {
+				Host string `env:"HOST" required:"true"`
+			} `prefix:"REPLICA_"`
+		} `prefix:"DB_"`
+	}
+	var cfg config
+	if err := ProcessConfig(context.Background(), "NESTED_", &cfg); err != nil {
+		t.Fatalf("ProcessConfig failed: %v", err)
+	}
+	if cfg.DB.Host != "db.internal" {
+		t.Errorf("Expected DB.Host 'db.internal', got %q", cfg.DB.Host)
+	}
+	if cfg.DB.Port != 5432 {
+		t.Errorf("Expected DB.Port 5432, got %d", cfg.DB.Port)
+	}
+	if cfg.DB.Replica == nil || cfg.DB.Replica.Host != "replica.internal" {
+		t.Errorf("Expected DB.Replica.Host 'replica.internal', got %+v", cfg.DB.Replica)
+	}
+}
@@ -0,0 +1,158 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// readConfigFile reads a YAML, JSON or TOML config file, selected by its extension
+// (.yaml/.yml, .json, .toml), into a map of keys to values.
+func readConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		var raw map[interface{}]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		values, _ = normalizeConfigValue(raw).(map[string]interface{})
+	case ".json":
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("invalid TOML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q (expected .yaml, .yml, .json or .toml)", ext)
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	return values, nil
+}
+
+// normalizeConfigValue converts the map[interface{}]interface{} values produced by
+// the YAML decoder into map[string]interface{}, recursively.
+func normalizeConfigValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalizeConfigValue(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = normalizeConfigValue(value)
+		}
+	}
+	return v
+}
+
+// mergeConfig merges src into dst. Nested maps are merged recursively;
+// any other value in src replaces the value in dst.
+func mergeConfig(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeConfig(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// lookupConfigValue returns the value at the key path in values.
+// Keys are matched exactly first, then case-insensitively.
+func lookupConfigValue(values map[string]interface{}, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	value, ok := values[path[0]]
+	if !ok {
+		for key, v := range values {
+			if strings.EqualFold(key, path[0]) {
+				value, ok = v, true
+				break
+			}
+		}
+	}
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+	nested, isMap := value.(map[string]interface{})
+	if !isMap {
+		return nil, false
+	}
+	return lookupConfigValue(nested, path[1:])
+}
+
+// configValueString converts a decoded config file value into the raw string form
+// accepted by setFieldValue. Lists are joined with the field's separator and maps
+// are rendered as key/value entries.
+func configValueString(value interface{}, opts valueOptions) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			parts[i] = configValueString(elem, opts)
+		}
+		return strings.Join(parts, opts.separator)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]string, len(keys))
+		for i, key := range keys {
+			entries[i] = key + opts.kvSeparator + configValueString(v[key], opts)
+		}
+		return strings.Join(entries, opts.separator)
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice {
+		parts := make([]string, rv.Len())
+		for i := range parts {
+			parts[i] = configValueString(rv.Index(i).Interface(), opts)
+		}
+		return strings.Join(parts, opts.separator)
+	}
+	return fmt.Sprint(value)
+}
+
+// splitConfigPaths splits a comma separated list of config file paths.
+func splitConfigPaths(value string) []string {
+	var paths []string
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,126 @@
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestFile writes content to name in dir and returns its path.
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+type fileTestConfig struct {
+	Name    string            `env:"NAME"`
+	Port    int               `env:"PORT" default:"80"`
+	Timeout time.Duration     `yaml:"timeout"`
+	Origins []string          `file:"allowed_origins"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	DB      struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	} `prefix:"DB_" file:"database"`
+	Internal string `file:"-"`
+}
+
+// TestLoaderConfigFiles tests that YAML, JSON and TOML files are merged in order.
+func TestLoaderConfigFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	yamlPath := writeTestFile(t, dir, "config.yaml", `
+name: from-yaml
+port: 8080
+timeout: 5s
+allowed_origins: [a.com, b.com]
+labels:
+  env: dev
+database:
+  host: yaml-db
+  port: 5432
+internal: leaked
+`)
+	jsonPath := writeTestFile(t, dir, "override.json", `{"labels": {"env": "prod", "team": "core"}, "database": {"host": "json-db"}}`)
+	tomlPath := writeTestFile(t, dir, "flag.toml", "port = 9090\n")
+
+	l := newTestLoader(
+		[]string{"--config", tomlPath},
+		map[string]string{"APP_CONFIG": jsonPath, "DB_PORT": "6543"},
+		WithConfigFiles(yamlPath),
+		WithConfigEnv("APP_CONFIG"),
+		WithConfigFlag("config"),
+	)
+
+	var cfg fileTestConfig
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Name != "from-yaml" {
+		t.Errorf("Expected Name 'from-yaml', got %q", cfg.Name)
+	}
+	if cfg.Port != 9090 { // flag file overrides explicit file
+		t.Errorf("Expected Port 9090, got %d", cfg.Port)
+	}
+	if cfg.Timeout != 5*time.Second {
+		t.Errorf("Expected Timeout 5s, got %v", cfg.Timeout)
+	}
+	if !reflect.DeepEqual(cfg.Origins, []string{"a.com", "b.com"}) {
+		t.Errorf("Expected Origins [a.com b.com], got %v", cfg.Origins)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]string{"env": "prod", "team": "core"}) {
+		t.Errorf("Expected Labels map[env:prod team:core], got %v", cfg.Labels)
+	}
+	if cfg.DB.Host != "json-db" {
+		t.Errorf("Expected DB.Host 'json-db', got %q", cfg.DB.Host)
+	}
+	if cfg.DB.Port != 6543 { // environment overrides file
+		t.Errorf("Expected DB.Port 6543, got %d", cfg.DB.Port)
+	}
+	if cfg.Internal != "" {
+		t.Errorf("Expected Internal to be excluded from files, got %q", cfg.Internal)
+	}
+}
+
+// TestLoaderConfigFileErrors tests that unreadable and unsupported files are reported.
+func TestLoaderConfigFileErrors(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	iniPath := writeTestFile(t, dir, "config.ini", "port=1")
+	badPath := writeTestFile(t, dir, "bad.json", "{")
+
+	l := newTestLoader(nil, nil, WithConfigFiles(filepath.Join(dir, "missing.yaml"), iniPath, badPath))
+	var cfg fileTestConfig
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil {
+		t.Fatal("Expected config file errors, got nil")
+	}
+	for _, want := range []string{"missing.yaml", "unsupported config file format", "invalid JSON"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got: %v", want, err)
+		}
+	}
+}
+
+// TestLookupConfigValue tests nested and case-insensitive key lookup.
+func TestLookupConfigValue(t *testing.T) {
+	values := map[string]interface{}{
+		"Server": map[string]interface{}{"port": 8080.0},
+	}
+	v, ok := lookupConfigValue(values, []string{"server", "port"})
+	if !ok || configValueString(v, valueOptionsFromTag("")) != "8080" {
+		t.Errorf("lookupConfigValue(server.port) = %v, %t; want 8080, true", v, ok)
+	}
+	if _, ok := lookupConfigValue(values, []string{"server", "port", "x"}); ok {
+		t.Errorf("Expected lookup below a scalar to fail")
+	}
+}
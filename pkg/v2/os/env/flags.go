@@ -0,0 +1,202 @@
+package env
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// boundFlag is the flag.Value bound to every flag defined by a Loader.
+// It records the raw value of each occurrence on the command line, so that flag
+// values are decoded by setFieldValue exactly like every other source.
+type boundFlag struct {
+	// values holds the raw value of each occurrence (only the last one unless repeatable).
+	values []string
+	// defValue is the default of the active profile (`default.<profile>`, else `default`), shown in usage output.
+	defValue string
+	// repeatable collects every occurrence (slice and map fields).
+	repeatable bool
+	// isBool allows the flag to be set without a value (--debug).
+	isBool bool
+	// check validates a raw value when the flag is parsed.
+	check func(string) error
+}
+
+// String returns the raw value(s) of the flag, or its default if it was not set.
+func (b *boundFlag) String() string {
+	if b == nil {
+		return ""
+	}
+	if len(b.values) == 0 {
+		return b.defValue
+	}
+	return strings.Join(b.values, ",")
+}
+
+// Set records one occurrence of the flag.
+func (b *boundFlag) Set(value string) error {
+	if b.check != nil {
+		if err := b.check(value); err != nil {
+			return err
+		}
+	}
+	if b.repeatable {
+		b.values = append(b.values, value)
+	} else {
+		b.values = []string{value}
+	}
+	return nil
+}
+
+// IsBoolFlag reports whether the flag can be set without a value.
+func (b *boundFlag) IsBoolFlag() bool { return b.isBool }
+
+// reset forgets all recorded occurrences ahead of a new parse.
+func (b *boundFlag) reset() { b.values = nil }
+
+// occurrences returns a copy of the recorded raw values.
+func (b *boundFlag) occurrences() []string {
+	return append([]string(nil), b.values...)
+}
+
+// isSupportedType reports whether setFieldValue can convert a string into typ.
+func isSupportedType(typ reflect.Type) bool {
+	if hasCustomDecoder(typ) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return isSupportedType(typ.Elem())
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return isSupportedType(typ.Elem())
+	case reflect.Map:
+		return isSupportedType(typ.Key()) && isSupportedType(typ.Elem())
+	}
+	return false
+}
+
+// isRepeatable reports whether a flag for typ collects every occurrence.
+// Slices (other than []byte) and maps are repeatable, unless decoded by a custom decoder.
+func isRepeatable(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if hasCustomDecoder(typ) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		return typ.Elem().Kind() != reflect.Uint8
+	case reflect.Map:
+		return true
+	}
+	return false
+}
+
+// defineFlags defines command-line flags on fs based on the `flag` tags of fields, with
+// the defaults of profile. Flags already defined on fs (by an earlier Load or by the caller)
+// are left untouched and their value is read as-is. Values of fields for which expand
+// reports true are not checked, as they may hold ${NAME} references. It returns the
+// number of flags it defined.
+func defineFlags(fs *flag.FlagSet, fields []fieldSpec, profile string, expand func(fieldSpec) bool) (int, error) {
+	var flagDefinitionErrors []FieldError
+	defined := 0
+
+	for _, f := range fields {
+		fieldName := f.Name
+		flagName := f.FlagName
+		if flagName == "" {
+			continue // No flag defined for this field
+		}
+		if fs.Lookup(flagName) != nil {
+			continue // Flag already defined
+		}
+
+		fieldType := f.Value.Type()
+		if !isSupportedType(fieldType) {
+			flagDefinitionErrors = append(flagDefinitionErrors, FieldError{
+				Field:  fieldName,
+				Source: SourceFlag,
+				Key:    "--" + flagName,
+				Kind:   ErrUnsupportedType,
+				msg:    fmt.Sprintf("field %q (flag %q): unsupported type for flag: %s", fieldName, flagName, fieldType),
+			})
+			continue
+		}
+
+		opts := valueOptionsFromTag(f.Field.Tag)
+		baseType := fieldType
+		if baseType.Kind() == reflect.Ptr {
+			baseType = baseType.Elem()
+		}
+		defValue, defaultKey := profileDefault(f.Field.Tag, profile)
+		if defaultKey == "" {
+			defaultKey = TagDefault
+		}
+		bound := &boundFlag{
+			defValue:   defValue,
+			repeatable: isRepeatable(fieldType),
+			isBool:     baseType.Kind() == reflect.Bool && !hasCustomDecoder(baseType),
+			check: func(value string) error {
+				return setFieldValue(reflect.New(fieldType).Elem(), value, opts)
+			},
+		}
+		if expand != nil && expand(f) {
+			bound.check = nil // Checked once expanded
+		}
+		if bound.defValue != "" && bound.check != nil {
+			if err := bound.check(bound.defValue); err != nil {
+				kind := errorKind(err)
+				err = redactedCause(f, err, "parse")
+				flagDefinitionErrors = append(flagDefinitionErrors, FieldError{
+					Field:    fieldName,
+					Source:   SourceDefault,
+					Key:      defaultKey,
+					RawValue: rawValue(f, bound.defValue),
+					Kind:     kind,
+					Err:      err,
+					msg:      fmt.Sprintf("field %q (flag %q): invalid %s value '%s': %v", fieldName, flagName, defaultKey, rawValue(f, bound.defValue), err),
+				})
+				continue // Skip defining this flag
+			}
+		}
+		if isSensitive(f) {
+			// The flag package quotes invalid values in its errors; check once loaded instead.
+			bound.check = nil
+		}
+
+		usage := f.Field.Tag.Get(TagDescription)
+		if usage == "" {
+			usage = fmt.Sprintf("Set value for %s", fieldName) // Basic usage message
+		}
+		if f.EnvKey != "" {
+			usage = fmt.Sprintf("%s (env: %s)", usage, f.EnvKey)
+		}
+		if bound.repeatable {
+			usage += " (repeatable)"
+		}
+
+		fs.Var(bound, flagName, usage)
+		defined++
+
+		// Aliases share the value of the canonical flag.
+		aliasUsage := "Alias of --" + flagName
+		if isDeprecated(f) {
+			aliasUsage = "Deprecated alias of --" + flagName
+		}
+		for _, alias := range f.FlagAliases {
+			if fs.Lookup(alias) == nil {
+				fs.Var(bound, alias, aliasUsage)
+				defined++
+			}
+		}
+	}
+
+	return defined, newConfigError("flag definition errors", flagDefinitionErrors)
+}
@@ -0,0 +1,642 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Loader loads configuration from defaults, .env files, environment variables,
+// secrets and command-line flags into struct specifications.
+//
+// A Loader holds no package-level state: Load can be called repeatedly with different
+// specs, and loaders configured with their own flag set, arguments and env lookup
+// function can be used from parallel tests.
+type Loader struct {
+	flagSet     *flag.FlagSet
+	args        []string
+	lookupEnv   func(string) (string, bool)
+	dotenvPaths []string
+	secrets     SecretProvider
+	// secretSchemes maps secret URI schemes (e.g., "file") to their providers.
+	secretSchemes map[string]SecretProvider
+	configFiles   []string
+	configFlag    string
+	configEnv     string
+	expand        bool
+	profile       string
+	profileEnv    string
+	envconfig     bool
+
+	// mu serializes flag definition and parsing on the (possibly shared) flag set.
+	mu             sync.Mutex
+	dotenvOverride bool
+	dotenvIsolated bool
+	dotenvDisabled bool
+
+	secretConcurrency int
+	secretTimeout     time.Duration
+	secretCacheTTL    time.Duration
+
+	// dotenvMu guards the dotenv state below.
+	dotenvMu sync.Mutex
+	// dotenvSet holds the environment variables set from .env files by this loader.
+	dotenvSet map[string]string
+	// dotenvPrev holds the values of the environment variables overridden from .env files.
+	dotenvPrev map[string]string
+	// dotenvValues holds the values read from .env files with WithDotenvIsolated.
+	dotenvValues map[string]string
+
+	// secretCacheMu guards secretCache.
+	secretCacheMu sync.Mutex
+	secretCache   map[string]secretCacheEntry
+
+	// warnedMu guards warned.
+	warnedMu sync.Mutex
+	// warned holds the deprecation warnings already logged, so that reloads don't repeat them.
+	warned map[string]bool
+}
+
+// Option configures a Loader.
+type Option func(*Loader)
+
+// WithFlagSet binds config flags to fs instead of flag.CommandLine.
+func WithFlagSet(fs *flag.FlagSet) Option {
+	return func(l *Loader) { l.flagSet = fs }
+}
+
+// WithArgs parses args instead of os.Args[1:].
+func WithArgs(args []string) Option {
+	return func(l *Loader) { l.args = args }
+}
+
+// WithLookupEnv reads environment variables with fn instead of os.LookupEnv.
+func WithLookupEnv(fn func(key string) (string, bool)) Option {
+	return func(l *Loader) { l.lookupEnv = fn }
+}
+
+// WithDotenvPaths loads the given .env files instead of ./.env, in order:
+// later files override earlier ones. Missing files are skipped.
+func WithDotenvPaths(paths ...string) Option {
+	return func(l *Loader) { l.dotenvPaths = paths }
+}
+
+// WithDotenvOverride gives values from .env files precedence over environment variables
+// that are already set. By default, like godotenv.Load, they do not override them.
+func WithDotenvOverride() Option {
+	return func(l *Loader) { l.dotenvOverride = true }
+}
+
+// WithDotenvIsolated keeps values from .env files in the loader instead of setting them
+// in the process environment with os.Setenv. They are only visible to the loader.
+func WithDotenvIsolated() Option {
+	return func(l *Loader) { l.dotenvIsolated = true }
+}
+
+// WithSecretProvider resolves `secret` tags without a URI scheme with p instead of Google Secret Manager.
+func WithSecretProvider(p SecretProvider) Option {
+	return func(l *Loader) { l.secrets = p }
+}
+
+// WithSecretConcurrency fetches at most n secrets at once. Default is DefaultSecretConcurrency.
+func WithSecretConcurrency(n int) Option {
+	return func(l *Loader) { l.secretConcurrency = n }
+}
+
+// WithSecretTimeout bounds each secret fetch, retries included, to d. Zero disables
+// the deadline. Default is DefaultSecretTimeout.
+func WithSecretTimeout(d time.Duration) Option {
+	return func(l *Loader) { l.secretTimeout = d }
+}
+
+// WithSecretCacheTTL caches fetched secrets in memory for ttl, across loads.
+// Secrets are not cached by default.
+func WithSecretCacheTTL(ttl time.Duration) Option {
+	return func(l *Loader) { l.secretCacheTTL = ttl }
+}
+
+// WithSecretScheme resolves `secret` tags of the form "scheme://name" with p, adding
+// a scheme or replacing one of the built-in "gcp", "file" and "env" providers.
+func WithSecretScheme(scheme string, p SecretProvider) Option {
+	return func(l *Loader) { l.secretSchemes[scheme] = p }
+}
+
+// WithConfigFiles loads the given YAML, JSON or TOML config files, in order.
+func WithConfigFiles(paths ...string) Option {
+	return func(l *Loader) { l.configFiles = paths }
+}
+
+// WithConfigFlag defines a repeatable flag (e.g., "config") naming additional config files.
+func WithConfigFlag(name string) Option {
+	return func(l *Loader) { l.configFlag = name }
+}
+
+// WithConfigEnv reads a comma separated list of additional config files from the
+// environment variable name (e.g., "APP_CONFIG"). The prefix is not applied.
+func WithConfigEnv(name string) Option {
+	return func(l *Loader) { l.configEnv = name }
+}
+
+// WithExpansion expands ${NAME} and ${NAME:-fallback} references in the values of all
+// fields not tagged `expand:"false"`, except values from secrets. Without it, only fields
+// tagged `expand:"true"` are expanded.
+func WithExpansion() Option {
+	return func(l *Loader) { l.expand = true }
+}
+
+// WithProfile sets the active profile (e.g., "prod") instead of reading it from the
+// APP_PROFILE environment variable.
+func WithProfile(name string) Option {
+	return func(l *Loader) { l.profile = name }
+}
+
+// WithProfileEnv reads the active profile from the environment variable name instead of
+// APP_PROFILE. An empty name disables reading the profile from the environment.
+func WithProfileEnv(name string) Option {
+	return func(l *Loader) { l.profileEnv = name }
+}
+
+// NewLoader returns a Loader configured with opts.
+//
+// Without options, the loader behaves like ProcessConfig: it binds flags to
+// flag.CommandLine, parses os.Args[1:], reads the process environment and ./.env,
+// and resolves secrets from Google Secret Manager.
+//
+// The "gcp://", "file://" and "env://" secret schemes are always available; the
+// "env" scheme reads variables through the loader's env lookup function.
+func NewLoader(opts ...Option) *Loader {
+	l := &Loader{
+		lookupEnv:     os.LookupEnv,
+		profileEnv:    DefaultProfileEnv,
+		secretTimeout: DefaultSecretTimeout,
+		secrets:       defaultSecretProvider,
+		secretSchemes: make(map[string]SecretProvider),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if _, ok := l.secretSchemes[SecretSchemeGCP]; !ok {
+		l.secretSchemes[SecretSchemeGCP] = defaultSecretProvider
+	}
+	if _, ok := l.secretSchemes[SecretSchemeFile]; !ok {
+		l.secretSchemes[SecretSchemeFile] = &FileSecretProvider{}
+	}
+	if _, ok := l.secretSchemes[SecretSchemeEnv]; !ok {
+		l.secretSchemes[SecretSchemeEnv] = &EnvSecretProvider{LookupEnv: l.lookup}
+	}
+	return l
+}
+
+// defaultLoader backs ProcessConfig.
+var defaultLoader = NewLoader()
+
+// flags returns the flag set to bind config flags to.
+// flag.CommandLine is resolved at use so that it can be replaced (e.g., in tests).
+func (l *Loader) flags() *flag.FlagSet {
+	if l.flagSet != nil {
+		return l.flagSet
+	}
+	return flag.CommandLine
+}
+
+// arguments returns the command-line arguments to parse.
+func (l *Loader) arguments() []string {
+	if l.args != nil {
+		return l.args
+	}
+	return os.Args[1:]
+}
+
+// bindFlags defines the flags for fields on the loader's flag set, with the defaults
+// of profile, parses the arguments and returns the raw values of the flags set on the
+// command line, and the positional arguments left after the flags.
+//
+// Arguments are parsed once, and again whenever a Load defines new flags, so a
+// second spec never silently misses its flags. Flags of specs not loaded yet are
+// unknown to the parse and fail it (or exit the process, with flag.CommandLine).
+func (l *Loader) bindFlags(fields []fieldSpec, profile string) (map[string][]string, []string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fs := l.flags()
+	defined, err := defineFlags(fs, fields, profile, l.expansionEnabled)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error defining flags: %w", err)
+	}
+	if l.configFlag != "" && fs.Lookup(l.configFlag) == nil {
+		fs.Var(&boundFlag{repeatable: true}, l.configFlag, "Config file to load (YAML, JSON or TOML; repeatable)")
+		defined++
+	}
+
+	if !fs.Parsed() || defined > 0 {
+		// Forget earlier occurrences so repeatable flags don't accumulate across parses.
+		fs.VisitAll(func(f *flag.Flag) {
+			if bound, ok := f.Value.(*boundFlag); ok {
+				bound.reset()
+			}
+		})
+		if err := fs.Parse(l.arguments()); err != nil {
+			return nil, nil, fmt.Errorf("error parsing flags: %w", err)
+		}
+	}
+
+	// Record which flags were actually set by the user
+	values := make(map[string][]string)
+	fs.Visit(func(f *flag.Flag) {
+		if bound, ok := f.Value.(*boundFlag); ok {
+			values[f.Name] = bound.occurrences()
+		} else {
+			// Flag defined by the caller rather than by a loader
+			values[f.Name] = []string{f.Value.String()}
+		}
+	})
+	return values, fs.Args(), nil
+}
+
+// configFilePaths returns the config files to load, in order: files given with
+// WithConfigFiles, then files named by the config env var, then by the config flag.
+func (l *Loader) configFilePaths(flagValues map[string][]string) []string {
+	paths := append([]string(nil), l.configFiles...)
+	if l.configEnv != "" {
+		if value, ok := l.lookup(l.configEnv); ok {
+			paths = append(paths, splitConfigPaths(value)...)
+		}
+	}
+	if l.configFlag != "" {
+		for _, value := range flagValues[l.configFlag] {
+			paths = append(paths, splitConfigPaths(value)...)
+		}
+	}
+	return paths
+}
+
+// readConfigFiles reads and merges the config files; later files override earlier ones.
+// The profile overlay of each file, if any, is merged right after it and may not exist.
+// It returns nil values if there are no config files.
+func (l *Loader) readConfigFiles(paths []string, profile string) (map[string]interface{}, []FieldError) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	var errs []FieldError
+	values := make(map[string]interface{})
+	for i, path := range withConfigOverlays(paths, profile) {
+		if profile != "" && i%2 == 1 && !fileExists(path) {
+			continue // Optional profile overlay
+		}
+		fileValues, err := readConfigFile(path)
+		if err != nil {
+			errs = append(errs, FieldError{
+				Source: SourceFile,
+				Key:    path,
+				Kind:   ErrParse,
+				Err:    err,
+				msg:    fmt.Sprintf("config file %q: %v", path, err),
+			})
+			continue
+		}
+		mergeConfig(values, fileValues)
+	}
+	return values, errs
+}
+
+// accessSecret resolves a `secret` tag value with the provider for its URI scheme,
+// or with the loader's default provider if it has none.
+func (l *Loader) accessSecret(ctx context.Context, name string) (string, error) {
+	provider := l.secrets
+	scheme, secretName := splitSecretScheme(name)
+	if scheme != "" {
+		var ok bool
+		if provider, ok = l.secretSchemes[scheme]; !ok {
+			return "", fmt.Errorf("unknown secret scheme %q", scheme)
+		}
+	}
+	if provider == nil {
+		return "", errors.New("no secret provider configured")
+	}
+	return provider.AccessSecret(ctx, secretName)
+}
+
+// Load processes configuration into spec. See ProcessConfig for the supported tags
+// and the order in which sources are applied.
+func (l *Loader) Load(ctx context.Context, prefix string, spec interface{}) error {
+	_, err := l.LoadWithReport(ctx, prefix, spec)
+	return err
+}
+
+// LoadWithReport processes configuration into spec like Load, and returns a Report
+// of the source each field got its value from. The report is returned alongside
+// any loading errors, but is nil if spec is invalid or flags cannot be parsed.
+func (l *Loader) LoadWithReport(ctx context.Context, prefix string, spec interface{}) (*Report, error) {
+	return l.load(ctx, prefix, spec, true)
+}
+
+// load processes configuration into spec. Command-line flags are only defined and
+// parsed if withFlags is set, so that typed accessors leave the flag set alone.
+func (l *Loader) load(ctx context.Context, prefix string, spec interface{}, withFlags bool) (*Report, error) {
+	// --- Validation ---
+	specValue := reflect.ValueOf(spec)
+	if specValue.Kind() != reflect.Ptr || specValue.IsNil() {
+		return nil, errInvalidSpecification
+	}
+	specElem := specValue.Elem()
+	if specElem.Kind() != reflect.Struct {
+		return nil, errInvalidSpecification
+	}
+	fields := collectFields(specElem, namePrefix{envconfig: l.envconfig})
+	if l.envconfig {
+		prefix = envconfigPrefix(prefix)
+	}
+
+	// --- Load Other Sources ---
+	// Load .env file (ignore if not found), and resolve the active profile, which
+	// selects the flag defaults
+	profile, dotenvPaths, processingErrors := l.loadDotenv()
+
+	// --- Define and Parse Flags ---
+	var flagValues map[string][]string
+	var args []string
+	if withFlags {
+		var err error
+		if flagValues, args, err = l.bindFlags(fields, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	// Load config files
+	configPaths := l.configFilePaths(flagValues)
+	fileValues, fileErrors := l.readConfigFiles(configPaths, profile)
+	processingErrors = append(processingErrors, fileErrors...)
+
+	// Fetch secrets
+	secretResults := l.fetchSecrets(ctx, fields)
+
+	report := &Report{
+		Profile: profile,
+		Files:   append(withConfigOverlays(configPaths, profile), dotenvPaths...),
+		Args:    args,
+	}
+
+	// --- Gather Raw Values ---
+	raws := make([]*rawField, 0, len(fields))
+	for _, fs := range fields {
+		fieldType := fs.Field
+		fieldName := fs.Name
+		opts := valueOptionsFromTag(fieldType.Tag)
+
+		var valueStr string
+		var found bool
+		var source string // Keep track of where the value came from (for debugging/info)
+		var provided []SourceValue
+
+		// --- 1. Apply Default Value ---
+		defaultValue, defaultKey := profileDefault(fieldType.Tag, profile)
+		if defaultValue != "" {
+			valueStr = defaultValue
+			found = true
+			source = SourceDefault
+			provided = append(provided, SourceValue{Source: source, Key: defaultKey, Value: valueStr})
+		}
+
+		// --- 2. Load from Config File ---
+		if fileValues != nil && fs.FileKey != nil {
+			if val, ok := lookupConfigValue(fileValues, fs.FileKey); ok {
+				valueStr = configValueString(val, opts)
+				found = true
+				source = SourceFile
+				provided = append(provided, SourceValue{Source: source, Key: strings.Join(fs.FileKey, "."), Value: valueStr})
+			}
+		}
+
+		// --- 3. Load from Environment Variable (from .env or actual env) ---
+		envKey := fs.EnvKey
+		if envKey != "" {
+			envFullName := strings.ToUpper(prefix + envKey)
+			val, fromDotenv, ok := l.lookupVar(envFullName)
+			for _, alias := range fs.EnvAliases {
+				if ok {
+					break
+				}
+				envFullName = strings.ToUpper(prefix + alias)
+				val, fromDotenv, ok = l.lookupVar(envFullName)
+			}
+			if !ok && fs.EnvAlt != "" {
+				envFullName = fs.EnvAlt
+				val, fromDotenv, ok = l.lookupVar(envFullName)
+			}
+			if ok {
+				valueStr = val
+				found = true
+				source = SourceEnvironment
+				if fromDotenv {
+					source = SourceDotenv
+				}
+				provided = append(provided, SourceValue{Source: source, Key: envFullName, Value: valueStr})
+			}
+		}
+
+		// --- 4. Load from Secret Provider ---
+		secretName := fs.SecretName
+		if secretName != "" {
+			name, selector := splitSecretSelector(secretName)
+			result := secretResults[name]
+			value, err := result.value, error(nil)
+			if selector != "" && result.err == nil {
+				if err = result.parseErr; err == nil {
+					value, err = selectSecretValue(result.payload, selector, opts)
+				}
+			}
+			switch {
+			case result.err != nil:
+				// Don't fail immediately, maybe another source worked or it's not required
+				processingErrors = append(processingErrors, FieldError{
+					Field:  fieldName,
+					Source: SourceSecret,
+					Key:    secretName,
+					Kind:   ErrSecretAccess,
+					Err:    result.err,
+					msg:    fmt.Sprintf("field %q: failed to access secret %q: %v", fieldName, name, result.err),
+				})
+			case err != nil:
+				processingErrors = append(processingErrors, FieldError{
+					Field:  fieldName,
+					Source: SourceSecret,
+					Key:    secretName,
+					Kind:   ErrParse,
+					Err:    err,
+					msg:    fmt.Sprintf("field %q: failed to select %q from secret %q: %v", fieldName, selector, name, err),
+				})
+			default:
+				valueStr = value
+				found = true
+				source = SourceSecret
+				provided = append(provided, SourceValue{Source: source, Key: secretName, Value: valueStr})
+			}
+		}
+
+		// --- 5. Load from Command-line Flag ---
+		// Check if the flag, or else one of its aliases, was set on the command line
+		flagName := fs.FlagName
+		raw, ok := flagValues[flagName]
+		for _, alias := range fs.FlagAliases {
+			if ok {
+				break
+			}
+			flagName = alias
+			raw, ok = flagValues[alias]
+		}
+		if ok && flagName != "" {
+			valueStr = strings.Join(raw, opts.separator)
+			found = true
+			source = SourceFlag
+			provided = append(provided, SourceValue{Source: source, Key: "--" + flagName, Value: valueStr})
+		}
+
+		for _, warning := range deprecationWarnings(prefix, fs, provided) {
+			l.warnOnce(warning)
+			report.Warnings = append(report.Warnings, warning)
+		}
+
+		raws = append(raws, &rawField{
+			fs:       fs,
+			value:    valueStr,
+			found:    found,
+			source:   source,
+			provided: provided,
+			expand:   l.expandsValue(fs, source),
+		})
+	}
+
+	// --- Process Fields ---
+	// Raw values are expanded once all are known, so that they can reference each other.
+	expander := newExpander(prefix, raws, l.lookup)
+	for _, rf := range raws {
+		// --- Expand References ---
+		// Expansion marks fields whose value includes a sensitive one, so read fs after it.
+		expanded, err := expander.expandField(rf, nil)
+		fs := rf.fs
+		field := fs.Value
+		fieldType := fs.Field
+		fieldName := fs.Name
+		opts := valueOptionsFromTag(fieldType.Tag)
+		valueStr, found, source, provided := rf.value, rf.found, rf.source, rf.provided
+		envKey, flagName, secretName := fs.EnvKey, fs.FlagName, fs.SecretName
+
+		if err != nil {
+			err = redactedCause(fs, err, "expand")
+			report.Fields = append(report.Fields, newFieldReport(fs, provided))
+			processingErrors = append(processingErrors, FieldError{
+				Field:    fieldName,
+				Source:   source,
+				Key:      provided[len(provided)-1].Key,
+				RawValue: rawValue(fs, valueStr),
+				Kind:     ErrParse,
+				Err:      err,
+				msg:      fmt.Sprintf("field %q (source: %s): error expanding value '%s': %v", fieldName, source, rawValue(fs, valueStr), err),
+			})
+			continue
+		}
+		valueStr = expanded
+
+		// --- Set Field Value ---
+		if found {
+			if err := setFieldValue(field, valueStr, opts); err != nil {
+				kind := errorKind(err)
+				err = redactedCause(fs, err, "parse")
+				report.Fields = append(report.Fields, newFieldReport(fs, provided))
+				processingErrors = append(processingErrors, FieldError{
+					Field:    fieldName,
+					Source:   source,
+					Key:      provided[len(provided)-1].Key,
+					RawValue: rawValue(fs, valueStr),
+					Kind:     kind,
+					Err:      err,
+					msg:      fmt.Sprintf("field %q (source: %s): error setting value '%s': %v", fieldName, source, rawValue(fs, valueStr), err),
+				})
+				continue // Skip required check if setting failed
+			}
+		}
+		report.Fields = append(report.Fields, newFieldReport(fs, provided))
+
+		// --- Check Required ---
+		required := fieldType.Tag.Get(TagRequired)
+		// Check IsZero AFTER attempting to set. Handles cases where the loaded value IS the zero value (e.g., port 0, empty string).
+		// If 'found' is false, it definitely wasn't set. If 'found' is true, check if the resulting field value is zero.
+		if required == "true" && (!found || field.IsZero()) {
+			// Construct a more informative error message
+			errMsg := fmt.Sprintf("field %q is required but was not provided", fieldName)
+			if found && field.IsZero() { // It was found, but the value resulted in zero
+				errMsg = fmt.Sprintf("field %q is required but received zero value (source: %s, raw value: '%s')", fieldName, source, rawValue(fs, valueStr))
+			}
+			envDetail := ""
+			if envKey != "" {
+				envDetail = fmt.Sprintf(" (env: %s%s)", prefix, envKey)
+			}
+			flagDetail := ""
+			if flagName != "" {
+				flagDetail = fmt.Sprintf(" (flag: --%s)", flagName)
+			}
+			secretDetail := ""
+			if secretName != "" {
+				secretDetail = fmt.Sprintf(" (secret: %s)", secretName)
+			}
+			processingErrors = append(processingErrors, FieldError{
+				Field:    fieldName,
+				Source:   source,
+				RawValue: rawValue(fs, valueStr),
+				Kind:     ErrRequired,
+				msg:      fmt.Sprintf("%s%s%s%s", errMsg, envDetail, flagDetail, secretDetail),
+			})
+		}
+
+		// --- Check Constraints ---
+		for _, fe := range validateField(fs, found) {
+			if n := len(provided); n > 0 {
+				fe.Source, fe.Key = provided[n-1].Source, provided[n-1].Key
+			}
+			processingErrors = append(processingErrors, fe)
+		}
+	} // End field loop
+
+	// --- Cross-Field Validation ---
+	if v, ok := spec.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			processingErrors = append(processingErrors, FieldError{
+				Kind: ErrValidation,
+				Err:  err,
+				msg:  fmt.Sprintf("validation failed: %v", err),
+			})
+		}
+	}
+
+	return report, newConfigError("", processingErrors)
+}
+
+// Close releases resources held by the loader's secret providers, if they hold any.
+// The Google Secret Manager provider shared with ProcessConfig and other loaders is
+// left open: close it with CloseSecretManagerClient.
+func (l *Loader) Close() error {
+	providers := []SecretProvider{l.secrets}
+	for _, p := range l.secretSchemes {
+		providers = append(providers, p)
+	}
+	var errs []error
+	closed := make(map[SecretProvider]bool)
+	for _, p := range providers {
+		closer, ok := p.(io.Closer)
+		if !ok || closed[p] || p == SecretProvider(defaultSecretProvider) {
+			continue
+		}
+		closed[p] = true
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
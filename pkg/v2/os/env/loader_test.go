@@ -0,0 +1,159 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mapEnv returns an env lookup function backed by a map.
+func mapEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+// newTestLoader returns a hermetic loader with its own flag set.
+func newTestLoader(args []string, env map[string]string, opts ...Option) *Loader {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if args == nil {
+		args = []string{}
+	}
+	return NewLoader(append([]Option{
+		WithFlagSet(fs),
+		WithArgs(args),
+		WithLookupEnv(mapEnv(env)),
+		WithDotenvPaths("testdata/does-not-exist.env"),
+		WithSecretProvider(NewMemorySecretProvider(nil)),
+	}, opts...)...)
+}
+
+type loaderTestConfig struct {
+	Host    string        `env:"HOST" flag:"host" default:"localhost"`
+	Port    int           `env:"PORT" flag:"port" default:"8080" required:"true"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+	Debug   bool          `env:"DEBUG" flag:"debug" default:"false"`
+	Tags    []string      `flag:"tag"`
+	APIKey  string        `env:"API_KEY" secret:"projects/p/secrets/k/versions/1"`
+}
+
+// TestLoaderPrecedence tests that flags override secrets, which override env, which override defaults.
+func TestLoaderPrecedence(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(
+		[]string{"--host=flaghost", "--debug", "--tag", "a", "--tag", "b"},
+		map[string]string{"TEST_HOST": "envhost", "TEST_PORT": "9090", "TEST_API_KEY": "envkey"},
+		WithSecretProvider(NewMemorySecretProvider(map[string]string{"projects/p/secrets/k/versions/1": "secretkey"})),
+	)
+
+	var cfg loaderTestConfig
+	if err := l.Load(context.Background(), "TEST_", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Host != "flaghost" {
+		t.Errorf("Expected Host 'flaghost', got %q", cfg.Host)
+	}
+	if cfg.Port != 9090 {
+		t.Errorf("Expected Port 9090, got %d", cfg.Port)
+	}
+	if cfg.Timeout != 5*time.Second {
+		t.Errorf("Expected Timeout 5s, got %v", cfg.Timeout)
+	}
+	if !cfg.Debug {
+		t.Errorf("Expected Debug true, got %t", cfg.Debug)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("Expected Tags [a b], got %v", cfg.Tags)
+	}
+	if cfg.APIKey != "secretkey" {
+		t.Errorf("Expected APIKey 'secretkey', got %q", cfg.APIKey)
+	}
+}
+
+// TestLoaderLoadTwice tests that a second Load with a different spec defines and parses its own flags.
+func TestLoaderLoadTwice(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader([]string{"--tag=a", "--name=svc"}, nil)
+
+	var first struct {
+		Tags []string `flag:"tag"`
+	}
+	if err := l.Load(context.Background(), "", &first); err == nil {
+		t.Fatalf("Expected error for undefined flag --name on first Load, got nil")
+	}
+
+	var second struct {
+		Tags []string `flag:"tag"`
+		Name string   `flag:"name"`
+	}
+	if err := l.Load(context.Background(), "", &second); err != nil {
+		t.Fatalf("Second Load failed: %v", err)
+	}
+	if second.Name != "svc" {
+		t.Errorf("Expected Name 'svc', got %q", second.Name)
+	}
+	// Re-parsing must not accumulate repeated occurrences
+	if !reflect.DeepEqual(second.Tags, []string{"a"}) {
+		t.Errorf("Expected Tags [a], got %v", second.Tags)
+	}
+}
+
+// TestLoaderErrors tests that required, parse and secret errors are aggregated.
+func TestLoaderErrors(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"PORT": "http"})
+
+	var cfg struct {
+		Port   int    `env:"PORT"`
+		Name   string `env:"NAME" required:"true"`
+		Secret string `secret:"missing"`
+	}
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil {
+		t.Fatal("Expected config loading errors, got nil")
+	}
+	for _, want := range []string{`"Port"`, `"Name" is required`, `secret "missing"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got: %v", want, err)
+		}
+	}
+}
+
+// TestLoaderInvalidSpec tests that only non-nil pointers to structs are accepted.
+func TestLoaderInvalidSpec(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, nil)
+	ctx := context.Background()
+
+	var cfg loaderTestConfig
+	var i int
+	var nilPtr *loaderTestConfig
+	for _, spec := range []interface{}{cfg, &i, nilPtr} {
+		if err := l.Load(ctx, "", spec); !errors.Is(err, errInvalidSpecification) {
+			t.Errorf("Expected errInvalidSpecification for %T, got %v", spec, err)
+		}
+	}
+}
+
+// TestDefineFlagsInvalidDefault tests that invalid defaults are reported when defining flags.
+func TestDefineFlagsInvalidDefault(t *testing.T) {
+	var cfg struct {
+		Port int `flag:"port" default:"eighty"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	_, err := defineFlags(fs, collectFields(reflect.ValueOf(&cfg).Elem(), namePrefix{}), "", nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid default value") {
+		t.Errorf("Expected invalid default error, got %v", err)
+	}
+	if fs.Lookup("port") != nil {
+		t.Errorf("Expected flag with invalid default not to be defined")
+	}
+}
@@ -0,0 +1,76 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// DefaultProfileEnv is the environment variable naming the active profile (e.g., "prod").
+// The prefix is not applied.
+const DefaultProfileEnv = "APP_PROFILE"
+
+// activeProfile returns the profile set with WithProfile, else the value of the
+// profile environment variable, looked up in the environment then in dotenv values.
+func (l *Loader) activeProfile(dotenv map[string]string) string {
+	if l.profile != "" || l.profileEnv == "" {
+		return l.profile
+	}
+	if profile, ok := l.lookupEnv(l.profileEnv); ok {
+		return profile
+	}
+	return dotenv[l.profileEnv]
+}
+
+// currentProfile returns the active profile, reading the dotenv values of an isolated
+// loader from its last Load.
+func (l *Loader) currentProfile() string {
+	l.dotenvMu.Lock()
+	defer l.dotenvMu.Unlock()
+	return l.activeProfile(l.dotenvValues)
+}
+
+// profileConfigPath returns the overlay of a config file for profile:
+// config.yaml becomes config.prod.yaml.
+func profileConfigPath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// profileDotenvPath returns the overlay of a .env file for profile: .env becomes .env.prod.
+func profileDotenvPath(path, profile string) string {
+	return path + "." + profile
+}
+
+// withConfigOverlays returns the config files followed, each, by its profile overlay.
+func withConfigOverlays(paths []string, profile string) []string {
+	if profile == "" {
+		return paths
+	}
+	all := make([]string, 0, 2*len(paths))
+	for _, path := range paths {
+		all = append(all, path, profileConfigPath(path, profile))
+	}
+	return all
+}
+
+// profileDefault returns the default value of a field for profile: the `default.<profile>`
+// tag if present, else the `default` tag. The key is the tag the value came from.
+func profileDefault(tag reflect.StructTag, profile string) (value, key string) {
+	if profile != "" {
+		key = TagDefault + "." + profile
+		if value, ok := tag.Lookup(key); ok {
+			return value, key
+		}
+	}
+	return tag.Get(TagDefault), ""
+}
+
+// fileExists reports whether path exists; other stat errors are reported as existing
+// so that reading the file surfaces them.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
@@ -0,0 +1,105 @@
+package env
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+type profileTestConfig struct {
+	Name     string `yaml:"name"`
+	Replicas int    `yaml:"replicas" default:"1" default.prod:"3"`
+	LogLevel string `env:"PROFILE_TEST_LOG_LEVEL" default:"debug"`
+}
+
+// TestProfileOverlays tests that the config file, .env file and default overlays of the
+// active profile take precedence over the base sources.
+func TestProfileOverlays(t *testing.T) {
+	dir := t.TempDir()
+	config := writeTestFile(t, dir, "config.yaml", "name: base\n")
+	writeTestFile(t, dir, "config.prod.yaml", "name: production\n")
+	dotenv := writeTestFile(t, dir, ".env", "APP_PROFILE=prod\nPROFILE_TEST_LOG_LEVEL=info\n")
+	writeTestFile(t, dir, ".env.prod", "PROFILE_TEST_LOG_LEVEL=warn\n")
+	for _, key := range []string{"APP_PROFILE", "PROFILE_TEST_LOG_LEVEL"} {
+		if _, ok := os.LookupEnv(key); ok {
+			t.Skipf("%s is set in the environment", key)
+		}
+		defer os.Unsetenv(key)
+	}
+
+	// The profile is read from the base .env file.
+	l := newTestLoader(nil, nil,
+		WithLookupEnv(os.LookupEnv), WithDotenvPaths(dotenv), WithConfigFiles(config))
+	var cfg profileTestConfig
+	report, err := l.LoadWithReport(context.Background(), "", &cfg)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Name != "production" || cfg.Replicas != 3 || cfg.LogLevel != "warn" {
+		t.Errorf("Expected prod overlays, got %+v", cfg)
+	}
+	if report.Profile != "prod" {
+		t.Errorf("Expected profile 'prod' in report, got %q", report.Profile)
+	}
+	if fr := report.Field("Replicas"); fr == nil || fr.Source != SourceDefault || fr.Key != "default.prod" {
+		t.Errorf("Expected Replicas from default.prod, got %+v", fr)
+	}
+}
+
+// TestProfileOption tests that WithProfile overrides APP_PROFILE and that missing
+// overlays are skipped.
+func TestProfileOption(t *testing.T) {
+	t.Parallel()
+	config := writeTestFile(t, t.TempDir(), "config.yaml", "name: base\n")
+	l := newTestLoader(nil, map[string]string{"APP_PROFILE": "prod"},
+		WithProfile("staging"), WithConfigFiles(config))
+
+	var cfg profileTestConfig
+	report, err := l.LoadWithReport(context.Background(), "", &cfg)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Name != "base" || cfg.Replicas != 1 {
+		t.Errorf("Expected base values for the staging profile, got %+v", cfg)
+	}
+	if report.Profile != "staging" {
+		t.Errorf("Expected profile 'staging' in report, got %q", report.Profile)
+	}
+
+	l = newTestLoader(nil, map[string]string{"APP_PROFILE": "prod"}, WithProfileEnv(""))
+	if report, err = l.LoadWithReport(context.Background(), "", &cfg); err != nil || report.Profile != "" {
+		t.Errorf("Expected no profile with WithProfileEnv(\"\"), got %q, %v", report.Profile, err)
+	}
+}
+
+// TestProfileFlagDefaults tests that flags are defined with, and check, the defaults of the active profile.
+func TestProfileFlagDefaults(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"APP_PROFILE": "prod"})
+
+	var cfg struct {
+		Level string `flag:"level" default:"info" default.prod:"warn"`
+	}
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if def := l.flags().Lookup("level").DefValue; def != "warn" {
+		t.Errorf("Expected flag default 'warn', got %q", def)
+	}
+	var sb strings.Builder
+	if err := l.WriteUsage(&sb, "", &cfg); err != nil {
+		t.Fatalf("WriteUsage failed: %v", err)
+	}
+	if usage := sb.String(); !strings.Contains(usage, "warn") || strings.Contains(usage, "info") {
+		t.Errorf("Expected usage to show the prod default, got:\n%s", sb.String())
+	}
+
+	var invalid struct {
+		Port int `flag:"port" default:"80" default.prod:"eighty"`
+	}
+	err := l.Load(context.Background(), "", &invalid)
+	if err == nil || !strings.Contains(err.Error(), `invalid default.prod value 'eighty'`) {
+		t.Errorf("Expected invalid default.prod error, got %v", err)
+	}
+}
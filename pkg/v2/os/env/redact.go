@@ -0,0 +1,89 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RedactedSpec wraps a spec so that it can be printed or logged with the values of
+// sensitive fields (tagged `sensitive:"true"` or loaded from a secret) replaced by
+// RedactedValue. It implements fmt.Formatter and json.Marshaler.
+//
+// Unlike the Report, RedactedSpec does not know which values were expanded from
+// sensitive fields (e.g., a DSN built from `${PASSWORD}`): tag those `sensitive:"true"`.
+type RedactedSpec struct {
+	spec interface{}
+}
+
+// Redacted returns a wrapper printing spec, a struct or a pointer to a struct, with
+// its sensitive fields redacted, e.g. log.Printf("config: %v", env.Redacted(&cfg)).
+func Redacted(spec interface{}) RedactedSpec {
+	return RedactedSpec{spec: spec}
+}
+
+// redactedField is the name and printable value of a field.
+type redactedField struct {
+	name  string
+	value string
+}
+
+// fields returns the leaf fields of the spec, in declaration order.
+func (r RedactedSpec) fields() []redactedField {
+	v := reflect.ValueOf(r.spec)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []redactedField
+	for _, fs := range collectFieldsOfCopy(v) {
+		value := formatValue(fs.Value)
+		if isSensitive(fs) {
+			value = RedactedValue
+		}
+		fields = append(fields, redactedField{name: fs.Name, value: value})
+	}
+	return fields
+}
+
+// Fields returns the values of the fields of the spec keyed by their dotted path
+// (e.g., "DB.Host"), for structured loggers.
+func (r RedactedSpec) Fields() map[string]interface{} {
+	fields := r.fields()
+	m := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		m[f.name] = f.value
+	}
+	return m
+}
+
+// String renders the spec as space separated "Path=value" pairs, in declaration order.
+func (r RedactedSpec) String() string {
+	fields := r.fields()
+	if fields == nil {
+		return fmt.Sprintf("%%!(env.RedactedSpec=%T)", r.spec)
+	}
+	pairs := make([]string, len(fields))
+	for i, f := range fields {
+		pairs[i] = f.name + "=" + f.value
+	}
+	return "{" + strings.Join(pairs, " ") + "}"
+}
+
+// Format implements fmt.Formatter, so that no verb prints the values of sensitive fields.
+func (r RedactedSpec) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'q':
+		fmt.Fprintf(f, "%q", r.String())
+	default:
+		fmt.Fprint(f, r.String())
+	}
+}
+
+// MarshalJSON renders the spec as a JSON object of Fields.
+func (r RedactedSpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Fields())
+}
@@ -0,0 +1,59 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type redactTestConfig struct {
+	Host     string `env:"HOST"`
+	Password string `env:"PASSWORD" secret:"db-password"`
+	DB       *struct {
+		Token string `env:"TOKEN" sensitive:"true"`
+		Port  int    `env:"PORT"`
+	}
+}
+
+// TestRedacted tests that no format of a redacted spec shows sensitive values.
+func TestRedacted(t *testing.T) {
+	cfg := &redactTestConfig{Host: "localhost", Password: "s3cret"}
+	cfg.DB = &struct {
+		Token string `env:"TOKEN" sensitive:"true"`
+		Port  int    `env:"PORT"`
+	}{Token: "t0ken", Port: 5432}
+
+	want := "{Host=localhost Password=***REDACTED*** DB.Token=***REDACTED*** DB.Port=5432}"
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if got := fmt.Sprintf(format, Redacted(cfg)); got != want {
+			t.Errorf("Expected %q for %s, got %q", want, format, got)
+		}
+	}
+	if got := Redacted(*cfg).String(); got != want {
+		t.Errorf("Expected %q for a struct value, got %q", want, got)
+	}
+
+	fields := Redacted(cfg).Fields()
+	if fields["DB.Port"] != "5432" || fields["Password"] != RedactedValue {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+	data, err := json.Marshal(Redacted(cfg))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") || strings.Contains(string(data), "t0ken") {
+		t.Errorf("Expected JSON to be redacted, got %s", data)
+	}
+}
+
+// TestRedactedNilNested tests that redacting leaves nil nested structs untouched.
+func TestRedactedNilNested(t *testing.T) {
+	cfg := &redactTestConfig{Host: "localhost"}
+	if got := Redacted(cfg).String(); !strings.Contains(got, "DB.Port=0") {
+		t.Errorf("Expected nested fields with zero values, got %q", got)
+	}
+	if cfg.DB != nil {
+		t.Errorf("Expected the nested struct to stay nil")
+	}
+}
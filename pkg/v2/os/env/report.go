@@ -0,0 +1,156 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Sources a field value can come from, in increasing order of precedence.
+const (
+	SourceDefault     = "default"
+	SourceFile        = "file"
+	SourceDotenv      = "dotenv"
+	SourceEnvironment = "environment"
+	SourceSecret      = "secret"
+	SourceFlag        = "flag"
+)
+
+// RedactedValue replaces the value of sensitive fields in reports.
+const RedactedValue = "***REDACTED***"
+
+// Report describes where each field of a loaded spec got its value from.
+// It is safe to log: values of sensitive fields are redacted.
+type Report struct {
+	// Profile is the active profile, if any.
+	Profile string        `json:"profile,omitempty"`
+	Fields  []FieldReport `json:"fields"`
+	// Files lists the config and .env files the loader read from, if they exist.
+	Files []string `json:"files,omitempty"`
+	// Args holds the positional arguments left after the flags.
+	Args []string `json:"args,omitempty"`
+	// Warnings lists the deprecated keys the values were loaded from (see TagDeprecated).
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// FieldReport describes the provenance of a single field.
+type FieldReport struct {
+	// Field is the dotted Go path of the field, e.g. "DB.Host".
+	Field string `json:"field"`
+	// Value is the final value of the field, or RedactedValue.
+	Value string `json:"value"`
+	// Source is the source that provided the final value; empty if no source did.
+	Source string `json:"source,omitempty"`
+	// Key is the name the value was found under in Source (env var, flag, secret or file key).
+	Key string `json:"key,omitempty"`
+	// Overridden lists the lower-priority sources that also provided a value.
+	Overridden []SourceValue `json:"overridden,omitempty"`
+	// Sensitive is set if the values of the field are redacted.
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// SourceValue is a raw value provided by one source for a field.
+type SourceValue struct {
+	Source string `json:"source"`
+	Key    string `json:"key,omitempty"`
+	Value  string `json:"value"`
+}
+
+// Field returns the report for the field with the given dotted path, or nil.
+func (r *Report) Field(name string) *FieldReport {
+	if r == nil {
+		return nil
+	}
+	for i := range r.Fields {
+		if r.Fields[i].Field == name {
+			return &r.Fields[i]
+		}
+	}
+	return nil
+}
+
+// String renders the report as an aligned table, one field per line.
+func (r *Report) String() string {
+	if r == nil {
+		return ""
+	}
+	var sb strings.Builder
+	if r.Profile != "" {
+		fmt.Fprintf(&sb, "PROFILE: %s\n", r.Profile)
+	}
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tSOURCE\tKEY\tVALUE\tOVERRIDDEN")
+	for _, f := range r.Fields {
+		source := f.Source
+		if source == "" {
+			source = "-"
+		}
+		overridden := make([]string, len(f.Overridden))
+		for i, o := range f.Overridden {
+			overridden[i] = fmt.Sprintf("%s=%q", o.Source, o.Value)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%q\t%s\n", f.Field, source, f.Key, f.Value, strings.Join(overridden, ", "))
+	}
+	w.Flush()
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(&sb, "WARNING: %s\n", warning)
+	}
+	return sb.String()
+}
+
+// isSensitive reports whether the values of a field must be redacted: fields tagged
+// `sensitive:"true"`, fields that can be loaded from a secret and fields whose value
+// was expanded from a sensitive one.
+func isSensitive(fs fieldSpec) bool {
+	return fs.Sensitive || fs.Field.Tag.Get(TagSensitive) == "true" || fs.SecretName != ""
+}
+
+// newFieldReport builds the report for a field from the values provided by each
+// source, in order of precedence. The last provided value is the one that won.
+func newFieldReport(fs fieldSpec, provided []SourceValue) FieldReport {
+	fr := FieldReport{
+		Field:     fs.Name,
+		Value:     formatValue(fs.Value),
+		Sensitive: isSensitive(fs),
+	}
+	if n := len(provided); n > 0 {
+		fr.Source = provided[n-1].Source
+		fr.Key = provided[n-1].Key
+		fr.Overridden = provided[:n-1]
+	}
+	if fr.Sensitive {
+		fr.redact()
+	}
+	return fr
+}
+
+// redact marks the field as sensitive and redacts its values.
+func (fr *FieldReport) redact() {
+	fr.Sensitive = true
+	fr.Value = RedactedValue
+	for i := range fr.Overridden {
+		fr.Overridden[i].Value = RedactedValue
+	}
+}
+
+// formatValue renders a field value for reports, dereferencing pointers.
+func formatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+	if v.CanInterface() {
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+		if v.CanAddr() {
+			if s, ok := v.Addr().Interface().(fmt.Stringer); ok {
+				return s.String()
+			}
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,73 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestLoaderReport tests that the report records the winning and overridden sources.
+func TestLoaderReport(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(
+		[]string{"--port=9090"},
+		map[string]string{"APP_PORT": "8081", "APP_PASSWORD": "env-password"},
+		WithSecretProvider(NewMemorySecretProvider(map[string]string{"db-password": "s3cret"})),
+	)
+
+	var cfg struct {
+		Host     string `env:"HOST" default:"localhost"`
+		Port     int    `env:"PORT" flag:"port" default:"8080"`
+		Password string `env:"PASSWORD" secret:"db-password"`
+		Token    string `env:"TOKEN" default:"dev-token" sensitive:"true"`
+		Unset    string `env:"UNSET"`
+	}
+	report, err := l.LoadWithReport(context.Background(), "APP_", &cfg)
+	if err != nil {
+		t.Fatalf("LoadWithReport failed: %v", err)
+	}
+	if len(report.Fields) != 5 {
+		t.Fatalf("Expected 5 fields in report, got %d", len(report.Fields))
+	}
+
+	port := report.Field("Port")
+	if port == nil || port.Source != SourceFlag || port.Key != "--port" || port.Value != "9090" {
+		t.Errorf("Unexpected report for Port: %+v", port)
+	}
+	wantOverridden := []SourceValue{
+		{Source: SourceDefault, Value: "8080"},
+		{Source: SourceEnvironment, Key: "APP_PORT", Value: "8081"},
+	}
+	if !reflect.DeepEqual(port.Overridden, wantOverridden) {
+		t.Errorf("Expected Port overridden %+v, got %+v", wantOverridden, port.Overridden)
+	}
+
+	password := report.Field("Password")
+	if password.Source != SourceSecret || password.Value != RedactedValue || password.Overridden[0].Value != RedactedValue {
+		t.Errorf("Expected Password to be redacted, got %+v", password)
+	}
+	if token := report.Field("Token"); token.Value != RedactedValue || !token.Sensitive {
+		t.Errorf("Expected Token to be redacted, got %+v", token)
+	}
+	if unset := report.Field("Unset"); unset.Source != "" || unset.Value != "" {
+		t.Errorf("Expected Unset to have no source, got %+v", unset)
+	}
+
+	text := report.String()
+	if strings.Contains(text, "s3cret") || strings.Contains(text, "env-password") || strings.Contains(text, "dev-token") {
+		t.Errorf("Report text leaks a sensitive value:\n%s", text)
+	}
+	if !strings.Contains(text, "Port") || !strings.Contains(text, "flag") {
+		t.Errorf("Report text is missing the Port row:\n%s", text)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("Report JSON leaks a sensitive value: %s", data)
+	}
+}
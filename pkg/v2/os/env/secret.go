@@ -0,0 +1,305 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/finiteloopme/goutils/pkg/gcp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SecretProvider resolves the value of a secret referenced by a `secret` tag.
+type SecretProvider interface {
+	// AccessSecret returns the payload of the named secret.
+	AccessSecret(ctx context.Context, name string) (string, error)
+}
+
+// Secret URI schemes understood in `secret` tags (e.g., `secret:"file://db-password"`).
+// Secret names without a scheme are resolved by the loader's default provider.
+const (
+	// SecretSchemeGCP resolves secrets from Google Secret Manager.
+	SecretSchemeGCP = "gcp"
+	// SecretSchemeFile resolves secrets from files, relative to /run/secrets.
+	SecretSchemeFile = "file"
+	// SecretSchemeEnv resolves secrets from other environment variables.
+	SecretSchemeEnv = "env"
+)
+
+// ErrSecretNotFound is returned by the built-in providers when a secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// splitSecretScheme splits a `secret` tag value into its URI scheme and the
+// provider-specific name. The scheme is empty if the value has none.
+func splitSecretScheme(value string) (scheme, name string) {
+	if i := strings.Index(value, "://"); i > 0 {
+		return value[:i], value[i+len("://"):]
+	}
+	return "", value
+}
+
+// defaultSecretProvider is the Google Secret Manager provider shared by ProcessConfig
+// and by loaders created without WithSecretProvider.
+var defaultSecretProvider = &GCPSecretProvider{}
+
+// Defaults for retrying Secret Manager calls.
+const (
+	DefaultSecretMaxAttempts    = 4
+	DefaultSecretInitialBackoff = 100 * time.Millisecond
+)
+
+// GCPSecretProvider resolves secrets from Google Secret Manager.
+// Secret names are secret version resource names
+// (e.g., "projects/PROJECT_ID/secrets/SECRET_NAME/versions/latest"), secret resource names,
+// which resolve to their latest version, or short names ("SECRET_NAME", or "SECRET_NAME@3"
+// to pin a version) resolved against ProjectID.
+//
+// Calls failing with a transient gRPC code (e.g., Unavailable) are retried with
+// exponential backoff, within the deadline of the context.
+//
+// The client is created on first use and assumes Application Default Credentials (ADC)
+// are configured correctly. The zero value is ready to use.
+type GCPSecretProvider struct {
+	// ProjectID resolves short secret names. Defaults to gcp.GetProjectID().
+	ProjectID string
+	// MaxAttempts is the number of attempts of a call. Defaults to DefaultSecretMaxAttempts.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled after each retry.
+	// Defaults to DefaultSecretInitialBackoff.
+	InitialBackoff time.Duration
+
+	mu        sync.Mutex
+	client    *secretmanager.Client
+	projectID string
+}
+
+// initClient initializes the Secret Manager client if needed.
+func (p *GCPSecretProvider) initClient(ctx context.Context) (*secretmanager.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		client, err := secretmanager.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create secret manager client: %w", err)
+		}
+		p.client = client
+	}
+	return p.client, nil
+}
+
+// resourceName returns the secret version resource name of a secret name.
+func (p *GCPSecretProvider) resourceName(name string) (string, error) {
+	if strings.HasPrefix(name, "projects/") {
+		if !strings.Contains(name, "/versions/") {
+			name += "/versions/latest"
+		}
+		return name, nil
+	}
+
+	secret, version, pinned := strings.Cut(name, "@")
+	if !pinned {
+		version = "latest"
+	}
+	p.mu.Lock()
+	if p.projectID == "" {
+		p.projectID = p.ProjectID
+		if p.projectID == "" {
+			p.projectID = gcp.GetProjectID()
+		}
+	}
+	projectID := p.projectID
+	p.mu.Unlock()
+	if projectID == "" {
+		return "", fmt.Errorf("cannot resolve secret %q: no project ID (set ProjectID or GCP_PROJECT)", name)
+	}
+	return fmt.Sprintf("projects/%s/secrets/%s/versions/%s", projectID, secret, version), nil
+}
+
+// AccessSecret fetches the payload of the given secret version.
+func (p *GCPSecretProvider) AccessSecret(ctx context.Context, name string) (string, error) {
+	name, err := p.resourceName(name)
+	if err != nil {
+		return "", err
+	}
+	client, err := p.initClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Build the request.
+	req := &secretmanagerpb.AccessSecretVersionRequest{
+		Name: name,
+	}
+
+	// Call the API, retrying transient failures.
+	var result *secretmanagerpb.AccessSecretVersionResponse
+	err = retryTransient(ctx, p.MaxAttempts, p.InitialBackoff, func() error {
+		var err error
+		result, err = client.AccessSecretVersion(ctx, req)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to access secret version %q: %w", name, err)
+	}
+
+	// Secret Manager payloads are limited to 64KiB.
+	// Ensure the payload isn't excessively large. Consider adding checks if needed.
+
+	return string(result.Payload.Data), nil
+}
+
+// Close closes the Secret Manager client if it was initialized.
+func (p *GCPSecretProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		err := p.client.Close()
+		p.client = nil // Reset client after closing
+		return err
+	}
+	return nil
+}
+
+// isTransient reports whether a failed gRPC call may succeed if retried.
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.Internal, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// retryTransient calls fn until it succeeds, fails with a non-transient error, maxAttempts
+// calls were made or ctx is done. It waits between calls with exponential backoff and jitter.
+func retryTransient(ctx context.Context, maxAttempts int, backoff time.Duration, fn func() error) error {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultSecretMaxAttempts
+	}
+	if backoff <= 0 {
+		backoff = DefaultSecretInitialBackoff
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !isTransient(err) || attempt == maxAttempts {
+			return err
+		}
+		// Wait between half and all of the backoff.
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		backoff *= 2
+	}
+}
+
+// CloseSecretManagerClient closes the Secret Manager client used by ProcessConfig if it was initialized.
+// It's good practice to call this when the application shuts down.
+func CloseSecretManagerClient() error {
+	return defaultSecretProvider.Close()
+}
+
+// DefaultSecretsDir is the directory FileSecretProvider reads relative secret names from.
+const DefaultSecretsDir = "/run/secrets"
+
+// FileSecretProvider resolves secrets from files, such as Docker and Kubernetes secret mounts.
+// Relative names are read from Dir (DefaultSecretsDir if empty); absolute paths are read as-is.
+// A single trailing newline is removed from the file contents.
+type FileSecretProvider struct {
+	Dir string
+}
+
+// AccessSecret returns the contents of the named secret file.
+func (p *FileSecretProvider) AccessSecret(_ context.Context, name string) (string, error) {
+	path := name
+	if !filepath.IsAbs(path) {
+		dir := p.Dir
+		if dir == "" {
+			dir = DefaultSecretsDir
+		}
+		path = filepath.Join(dir, name)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: file %q", ErrSecretNotFound, path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file %q: %w", path, err)
+	}
+	value := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
+}
+
+// EnvSecretProvider resolves secrets from other environment variables, so that a
+// secret can be injected under a name of the deployment's choosing.
+// LookupEnv defaults to os.LookupEnv.
+type EnvSecretProvider struct {
+	LookupEnv func(key string) (string, bool)
+}
+
+// AccessSecret returns the value of the environment variable name.
+func (p *EnvSecretProvider) AccessSecret(_ context.Context, name string) (string, error) {
+	lookupEnv := p.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	value, ok := lookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %q", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// MemorySecretProvider resolves secrets from an in-memory map.
+// It is intended as a fake in tests and is safe for concurrent use.
+type MemorySecretProvider struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+// NewMemorySecretProvider returns a MemorySecretProvider holding a copy of secrets.
+func NewMemorySecretProvider(secrets map[string]string) *MemorySecretProvider {
+	p := &MemorySecretProvider{secrets: make(map[string]string, len(secrets))}
+	for name, value := range secrets {
+		p.secrets[name] = value
+	}
+	return p
+}
+
+// AccessSecret returns the value stored for name.
+func (p *MemorySecretProvider) AccessSecret(_ context.Context, name string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	value, ok := p.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// Set stores value for name, replacing any previous value.
+func (p *MemorySecretProvider) Set(name, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.secrets == nil {
+		p.secrets = make(map[string]string)
+	}
+	p.secrets[name] = value
+}
+
+// Delete removes name.
+func (p *MemorySecretProvider) Delete(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.secrets, name)
+}
@@ -0,0 +1,132 @@
+package env
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Defaults for fetching secrets.
+const (
+	// DefaultSecretConcurrency is the maximum number of secrets fetched at once.
+	DefaultSecretConcurrency = 8
+	// DefaultSecretTimeout bounds each secret fetch, retries included.
+	DefaultSecretTimeout = 10 * time.Second
+)
+
+// secretResult is the outcome of fetching a secret. The payload of secrets referenced
+// with a key selector is parsed once, into payload or parseErr.
+type secretResult struct {
+	value string
+	err   error
+
+	payload  map[string]interface{}
+	parseErr error
+}
+
+// secretCacheEntry is a cached secret value.
+type secretCacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// fetchSecrets fetches the secrets referenced by fields concurrently, each secret once,
+// with at most l.secretConcurrency fetches in flight, each bounded by l.secretTimeout.
+// Secrets cached by an earlier Load are reused until they expire. Results are keyed
+// by secret name, without key selectors.
+func (l *Loader) fetchSecrets(ctx context.Context, fields []fieldSpec) map[string]*secretResult {
+	results := make(map[string]*secretResult)
+	selected := make(map[string]bool)
+	var names []string
+	for _, fs := range fields {
+		if fs.SecretName == "" {
+			continue
+		}
+		name, selector := splitSecretSelector(fs.SecretName)
+		if selector != "" {
+			selected[name] = true
+		}
+		if _, ok := results[name]; ok {
+			continue
+		}
+		if value, ok := l.cachedSecret(name); ok {
+			results[name] = &secretResult{value: value}
+			continue
+		}
+		results[name] = &secretResult{}
+		names = append(names, name)
+	}
+
+	workers := l.secretConcurrency
+	if workers <= 0 {
+		workers = DefaultSecretConcurrency
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			callCtx := ctx
+			if l.secretTimeout > 0 {
+				var cancel context.CancelFunc
+				callCtx, cancel = context.WithTimeout(ctx, l.secretTimeout)
+				defer cancel()
+			}
+			value, err := l.accessSecret(callCtx, name)
+			if err == nil {
+				l.cacheSecret(name, value)
+			}
+			mu.Lock()
+			results[name] = &secretResult{value: value, err: err}
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	for name := range selected {
+		if result := results[name]; result.err == nil {
+			result.payload, result.parseErr = parseSecretPayload(result.value)
+		}
+	}
+	return results
+}
+
+// cachedSecret returns the cached value of a secret, if the cache is enabled and it has not expired.
+func (l *Loader) cachedSecret(name string) (string, bool) {
+	if l.secretCacheTTL <= 0 {
+		return "", false
+	}
+	l.secretCacheMu.Lock()
+	defer l.secretCacheMu.Unlock()
+	entry, ok := l.secretCache[name]
+	if !ok || time.Now().After(entry.expires) {
+		delete(l.secretCache, name)
+		return "", false
+	}
+	return entry.value, true
+}
+
+// cacheSecret caches the value of a secret, if the cache is enabled.
+func (l *Loader) cacheSecret(name, value string) {
+	if l.secretCacheTTL <= 0 {
+		return
+	}
+	l.secretCacheMu.Lock()
+	defer l.secretCacheMu.Unlock()
+	if l.secretCache == nil {
+		l.secretCache = make(map[string]secretCacheEntry)
+	}
+	l.secretCache[name] = secretCacheEntry{value: value, expires: time.Now().Add(l.secretCacheTTL)}
+}
+
+// PurgeSecretCache drops the secrets cached by the loader, so that the next Load fetches them again.
+func (l *Loader) PurgeSecretCache() {
+	l.secretCacheMu.Lock()
+	defer l.secretCacheMu.Unlock()
+	l.secretCache = nil
+}
@@ -0,0 +1,175 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// countingSecretProvider counts calls and the maximum number of calls in flight.
+// Secrets not in values are "value-of-<name>".
+type countingSecretProvider struct {
+	delay  time.Duration
+	values map[string]string
+
+	mu       sync.Mutex
+	calls    map[string]int
+	inFlight int32
+	maxSeen  int32
+}
+
+func (p *countingSecretProvider) AccessSecret(ctx context.Context, name string) (string, error) {
+	n := atomic.AddInt32(&p.inFlight, 1)
+	defer atomic.AddInt32(&p.inFlight, -1)
+	for {
+		seen := atomic.LoadInt32(&p.maxSeen)
+		if n <= seen || atomic.CompareAndSwapInt32(&p.maxSeen, seen, n) {
+			break
+		}
+	}
+	p.mu.Lock()
+	if p.calls == nil {
+		p.calls = make(map[string]int)
+	}
+	p.calls[name]++
+	p.mu.Unlock()
+
+	select {
+	case <-time.After(p.delay):
+		if value, ok := p.values[name]; ok {
+			return value, nil
+		}
+		return "value-of-" + name, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (p *countingSecretProvider) count(name string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[name]
+}
+
+type secretFetchTestConfig struct {
+	A     string `secret:"a"`
+	B     string `secret:"b"`
+	C     string `secret:"c"`
+	D     string `secret:"d"`
+	AlsoA string `secret:"a"`
+}
+
+// TestFetchSecretsConcurrently tests that distinct secrets are fetched once each,
+// with a bounded number of fetches in flight.
+func TestFetchSecretsConcurrently(t *testing.T) {
+	p := &countingSecretProvider{delay: 20 * time.Millisecond}
+	l := newTestLoader(nil, nil, WithSecretProvider(p), WithSecretConcurrency(2))
+
+	var cfg secretFetchTestConfig
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.A != "value-of-a" || cfg.AlsoA != "value-of-a" || cfg.D != "value-of-d" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if n := p.count("a"); n != 1 {
+		t.Errorf("Expected secret a to be fetched once, got %d", n)
+	}
+	if maxSeen := atomic.LoadInt32(&p.maxSeen); maxSeen != 2 {
+		t.Errorf("Expected 2 fetches in flight, got %d", maxSeen)
+	}
+}
+
+// TestFetchSecretsCache tests that cached secrets are reused until purged.
+func TestFetchSecretsCache(t *testing.T) {
+	p := &countingSecretProvider{}
+	l := newTestLoader(nil, nil, WithSecretProvider(p), WithSecretCacheTTL(time.Hour))
+
+	var cfg secretFetchTestConfig
+	for i := 0; i < 2; i++ {
+		if err := l.Load(context.Background(), "", &cfg); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+	}
+	if n := p.count("b"); n != 1 {
+		t.Errorf("Expected secret b to be fetched once, got %d", n)
+	}
+
+	l.PurgeSecretCache()
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if n := p.count("b"); n != 2 {
+		t.Errorf("Expected secret b to be fetched again after purge, got %d", n)
+	}
+}
+
+// TestFetchSecretsTimeout tests that a slow secret fails with ErrSecretAccess.
+func TestFetchSecretsTimeout(t *testing.T) {
+	p := &countingSecretProvider{delay: time.Hour}
+	l := newTestLoader(nil, nil, WithSecretProvider(p), WithSecretTimeout(10*time.Millisecond))
+
+	var cfg secretFetchTestConfig
+	err := l.Load(context.Background(), "", &cfg)
+	if !errors.Is(err, ErrSecretAccess) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a secret access deadline error, got %v", err)
+	}
+}
+
+// TestGCPSecretResourceName tests the resolution of short and partial secret names.
+func TestGCPSecretResourceName(t *testing.T) {
+	p := &GCPSecretProvider{ProjectID: "proj"}
+	tests := map[string]string{
+		"db":                              "projects/proj/secrets/db/versions/latest",
+		"db@3":                            "projects/proj/secrets/db/versions/3",
+		"projects/p/secrets/s":            "projects/p/secrets/s/versions/latest",
+		"projects/p/secrets/s/versions/2": "projects/p/secrets/s/versions/2",
+	}
+	for name, want := range tests {
+		got, err := p.resourceName(name)
+		if err != nil || got != want {
+			t.Errorf("Expected %q for %q, got %q (%v)", want, name, got, err)
+		}
+	}
+}
+
+// TestRetryTransient tests that transient gRPC errors are retried, and others are not.
+func TestRetryTransient(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	err := retryTransient(ctx, 3, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return status.Error(codes.Unavailable, "try again")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success after 3 calls, got %d calls: %v", calls, err)
+	}
+
+	calls = 0
+	err = retryTransient(ctx, 3, time.Millisecond, func() error {
+		calls++
+		return status.Error(codes.NotFound, "no such secret")
+	})
+	if status.Code(err) != codes.NotFound || calls != 1 {
+		t.Errorf("Expected NotFound after 1 call, got %d calls: %v", calls, err)
+	}
+
+	calls = 0
+	err = retryTransient(ctx, 2, time.Millisecond, func() error {
+		calls++
+		return status.Error(codes.ResourceExhausted, "quota")
+	})
+	if !strings.Contains(err.Error(), "quota") || calls != 2 {
+		t.Errorf("Expected the last error after 2 calls, got %d calls: %v", calls, err)
+	}
+}
@@ -0,0 +1,61 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+)
+
+// splitSecretSelector splits a `secret` tag value of the form "name#key.path" into the
+// secret name and the key path selecting a value of its payload.
+func splitSecretSelector(value string) (name, selector string) {
+	name, selector, _ = strings.Cut(value, "#")
+	return name, selector
+}
+
+// parseSecretPayload parses a structured secret payload: a JSON object, a YAML mapping
+// or dotenv (KEY=value) lines.
+func parseSecretPayload(payload string) (map[string]interface{}, error) {
+	if strings.HasPrefix(strings.TrimSpace(payload), "{") {
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(payload), &values); err != nil {
+			// Syntax errors quote the payload; only report where it is malformed.
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return nil, fmt.Errorf("invalid JSON payload at offset %d", syntaxErr.Offset)
+			}
+			return nil, fmt.Errorf("invalid JSON payload: %w", err)
+		}
+		return values, nil
+	}
+
+	var raw map[interface{}]interface{}
+	if err := yaml.Unmarshal([]byte(payload), &raw); err == nil && len(raw) > 0 {
+		values, _ := normalizeConfigValue(raw).(map[string]interface{})
+		return values, nil
+	}
+
+	lines, err := godotenv.Unmarshal(payload)
+	if err != nil || len(lines) == 0 {
+		return nil, fmt.Errorf("payload is not a JSON object, YAML mapping or dotenv file")
+	}
+	values := make(map[string]interface{}, len(lines))
+	for key, value := range lines {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// selectSecretValue returns the value at the dotted key path of a parsed secret payload,
+// in the raw string form accepted by setFieldValue.
+func selectSecretValue(payload map[string]interface{}, selector string, opts valueOptions) (string, error) {
+	value, ok := lookupConfigValue(payload, strings.Split(selector, "."))
+	if !ok {
+		return "", fmt.Errorf("key %q not found in secret payload", selector)
+	}
+	return configValueString(value, opts), nil
+}
@@ -0,0 +1,79 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type secretPayloadTestConfig struct {
+	User     string   `secret:"db#user"`
+	Password string   `secret:"db#password"`
+	Port     int      `secret:"db#replica.port"`
+	Hosts    []string `secret:"db#replica.hosts"`
+	Raw      string   `secret:"db"`
+}
+
+// TestSecretPayloadSelectors tests that key selectors extract values from one secret,
+// fetched once.
+func TestSecretPayloadSelectors(t *testing.T) {
+	payloads := map[string]string{
+		"json": `{"user": "admin", "password": "s3cret", "replica": {"port": 5433, "hosts": ["a", "b"]}}`,
+		"yaml": "user: admin\npassword: s3cret\nreplica:\n  port: 5433\n  hosts: [a, b]\n",
+	}
+	for format, payload := range payloads {
+		p := &countingSecretProvider{values: map[string]string{"db": payload}}
+		l := newTestLoader(nil, nil, WithSecretProvider(p))
+
+		var cfg secretPayloadTestConfig
+		if err := l.Load(context.Background(), "", &cfg); err != nil {
+			t.Fatalf("%s: Load failed: %v", format, err)
+		}
+		if cfg.User != "admin" || cfg.Password != "s3cret" || cfg.Port != 5433 || cfg.Raw != payload {
+			t.Errorf("%s: Unexpected config: %+v", format, cfg)
+		}
+		if !reflect.DeepEqual(cfg.Hosts, []string{"a", "b"}) {
+			t.Errorf("%s: Expected hosts [a b], got %v", format, cfg.Hosts)
+		}
+		if n := p.count("db"); n != 1 {
+			t.Errorf("%s: Expected secret to be fetched once, got %d", format, n)
+		}
+	}
+}
+
+// TestSecretPayloadDotenv tests selecting a value from dotenv lines.
+func TestSecretPayloadDotenv(t *testing.T) {
+	var cfg struct {
+		Key string `secret:"api#API_KEY"`
+	}
+	secrets := NewMemorySecretProvider(map[string]string{"api": "API_KEY=abc\nAPI_URL=https://example.com\n"})
+	l := newTestLoader(nil, nil, WithSecretProvider(secrets))
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Key != "abc" {
+		t.Errorf("Expected abc, got %q", cfg.Key)
+	}
+}
+
+// TestSecretPayloadErrors tests missing keys and unstructured payloads.
+func TestSecretPayloadErrors(t *testing.T) {
+	var cfg struct {
+		Missing string `secret:"db#nope"`
+		Plain   string `secret:"plain#key"`
+	}
+	secrets := NewMemorySecretProvider(map[string]string{"db": `{"user": "admin"}`, "plain": "just a password"})
+	l := newTestLoader(nil, nil, WithSecretProvider(secrets))
+
+	err := l.Load(context.Background(), "", &cfg)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || len(configErr.Errors) != 2 {
+		t.Fatalf("Expected 2 field errors, got %v", err)
+	}
+	for _, fe := range configErr.Errors {
+		if fe.Kind != ErrParse || fe.Source != SourceSecret {
+			t.Errorf("Expected a secret parse error, got %v", &fe)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+)
+
+// TestFileSecretProvider tests reading secrets relative to a directory and by absolute path.
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "db-password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+	p := &FileSecretProvider{Dir: dir}
+	ctx := context.Background()
+
+	for _, name := range []string{"db-password", path} {
+		value, err := p.AccessSecret(ctx, name)
+		if err != nil {
+			t.Fatalf("AccessSecret(%q) failed: %v", name, err)
+		}
+		if value != "s3cret" {
+			t.Errorf("AccessSecret(%q) = %q; want 's3cret'", name, value)
+		}
+	}
+
+	if _, err := p.AccessSecret(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound for missing file, got %v", err)
+	}
+}
+
+// TestMemorySecretProvider tests setting, reading and deleting in-memory secrets.
+func TestMemorySecretProvider(t *testing.T) {
+	p := NewMemorySecretProvider(map[string]string{"a": "1"})
+	ctx := context.Background()
+
+	p.Set("b", "2")
+	if value, err := p.AccessSecret(ctx, "b"); err != nil || value != "2" {
+		t.Errorf("AccessSecret(b) = %q, %v; want '2', nil", value, err)
+	}
+	p.Delete("a")
+	if _, err := p.AccessSecret(ctx, "a"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound after Delete, got %v", err)
+	}
+}
+
+// TestLoaderSecretSchemes tests that secret URI schemes select the provider.
+func TestLoaderSecretSchemes(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("file-token"), 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+
+	l := newTestLoader(nil,
+		map[string]string{"INJECTED_PASSWORD": "env-password"},
+		WithSecretProvider(NewMemorySecretProvider(map[string]string{"api-key": "memory-key"})),
+		WithSecretScheme(SecretSchemeFile, &FileSecretProvider{Dir: dir}),
+		WithSecretScheme("vault", NewMemorySecretProvider(map[string]string{"kv/db": "vault-db"})),
+	)
+
+	var cfg struct {
+		APIKey   string `secret:"api-key"`
+		Token    string `secret:"file://token"`
+		Password string `secret:"env://INJECTED_PASSWORD"`
+		DB       string `secret:"vault://kv/db"`
+	}
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := map[string]string{
+		"APIKey":   "memory-key",
+		"Token":    "file-token",
+		"Password": "env-password",
+		"DB":       "vault-db",
+	}
+	got := map[string]string{"APIKey": cfg.APIKey, "Token": cfg.Token, "Password": cfg.Password, "DB": cfg.DB}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("Expected %s %q, got %q", name, w, got[name])
+		}
+	}
+
+	var unknown struct {
+		Value string `secret:"s3://bucket/key"`
+	}
+	if err := l.Load(context.Background(), "", &unknown); err == nil {
+		t.Errorf("Expected error for unknown secret scheme, got nil")
+	}
+}
+
+// closingSecretProvider counts the calls to Close.
+type closingSecretProvider struct {
+	SecretProvider
+	closes int
+}
+
+func (p *closingSecretProvider) Close() error {
+	p.closes++
+	return nil
+}
+
+// TestLoaderClose tests that Close closes the loader's providers once and leaves the shared default provider open.
+func TestLoaderClose(t *testing.T) {
+	client := &secretmanager.Client{}
+	defaultSecretProvider.mu.Lock()
+	defaultSecretProvider.client = client
+	defaultSecretProvider.mu.Unlock()
+	t.Cleanup(func() {
+		defaultSecretProvider.mu.Lock()
+		defaultSecretProvider.client = nil
+		defaultSecretProvider.mu.Unlock()
+	})
+
+	p := &closingSecretProvider{SecretProvider: NewMemorySecretProvider(nil)}
+	l := NewLoader(WithSecretProvider(p), WithSecretScheme("vault", p))
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if p.closes != 1 {
+		t.Errorf("Expected the provider to be closed once, got %d", p.closes)
+	}
+	if err := NewLoader().Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if defaultSecretProvider.client != client {
+		t.Errorf("Expected the default provider to stay open")
+	}
+}
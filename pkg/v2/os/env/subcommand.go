@@ -0,0 +1,55 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownSubcommand is returned by ProcessSubcommand when args name no registered subcommand.
+var ErrUnknownSubcommand = errors.New("unknown subcommand")
+
+// ProcessSubcommand dispatches on args[0] (e.g., os.Args[1:] of "app serve --port 80 extra")
+// and loads the spec registered for that subcommand in specs. The spec's flags are bound
+// to a flag set of its own, named after the subcommand and parsed against the remaining
+// arguments, so subcommands may reuse flag names. It returns the subcommand and the
+// positional arguments left after its flags.
+//
+// opts configure the loader of the subcommand as for NewLoader, except for the flag
+// set and arguments. Parsing -h or --help prints the usage of the subcommand's spec
+// and returns an error wrapping flag.ErrHelp.
+func ProcessSubcommand(ctx context.Context, prefix string, args []string, specs map[string]interface{}, opts ...Option) (name string, rest []string, err error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", nil, fmt.Errorf("%w: expected one of %s", ErrUnknownSubcommand, subcommandNames(specs))
+	}
+	name = args[0]
+	spec, ok := specs[name]
+	if !ok {
+		return name, nil, fmt.Errorf("%w %q: expected one of %s", ErrUnknownSubcommand, name, subcommandNames(specs))
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	l := NewLoader(append(opts, WithFlagSet(fs), WithArgs(args[1:]))...)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s:\n", name)
+		l.WriteUsage(fs.Output(), prefix, spec)
+	}
+	report, err := l.LoadWithReport(ctx, prefix, spec)
+	if report != nil {
+		rest = report.Args
+	}
+	return name, rest, err
+}
+
+// subcommandNames returns the sorted names of the subcommands in specs, for error messages.
+func subcommandNames(specs map[string]interface{}) string {
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,84 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+type serveTestConfig struct {
+	Port    int  `flag:"port" default:"8080"`
+	Verbose bool `flag:"v"`
+}
+
+type migrateTestConfig struct {
+	Port   int  `flag:"port" default:"5432"`
+	DryRun bool `flag:"dry-run"`
+}
+
+// subcommandTestOptions keeps subcommand tests hermetic.
+var subcommandTestOptions = []Option{
+	WithLookupEnv(mapEnv(nil)),
+	WithDotenvPaths("testdata/does-not-exist.env"),
+	WithSecretProvider(NewMemorySecretProvider(nil)),
+}
+
+// TestProcessSubcommand tests that the spec of the selected subcommand is loaded
+// from its own flag set, and that positional arguments are returned.
+func TestProcessSubcommand(t *testing.T) {
+	var serve serveTestConfig
+	var migrate migrateTestConfig
+	specs := map[string]interface{}{"serve": &serve, "migrate": &migrate}
+
+	name, rest, err := ProcessSubcommand(context.Background(), "",
+		[]string{"migrate", "--port=6543", "--dry-run", "up", "--port=1"}, specs, subcommandTestOptions...)
+	if err != nil {
+		t.Fatalf("ProcessSubcommand failed: %v", err)
+	}
+	if name != "migrate" {
+		t.Errorf("Expected subcommand migrate, got %q", name)
+	}
+	if migrate.Port != 6543 || !migrate.DryRun {
+		t.Errorf("Unexpected migrate config: %+v", migrate)
+	}
+	if serve.Port != 0 {
+		t.Errorf("Expected the serve config to be left untouched, got %+v", serve)
+	}
+	if !reflect.DeepEqual(rest, []string{"up", "--port=1"}) {
+		t.Errorf("Expected positional args [up --port=1], got %v", rest)
+	}
+
+	name, _, err = ProcessSubcommand(context.Background(), "", []string{"serve", "-v"}, specs, subcommandTestOptions...)
+	if err != nil || name != "serve" || serve.Port != 8080 || !serve.Verbose {
+		t.Errorf("Unexpected serve result %q %+v: %v", name, serve, err)
+	}
+}
+
+// TestProcessSubcommandErrors tests missing and unknown subcommands, and help requests.
+func TestProcessSubcommandErrors(t *testing.T) {
+	specs := map[string]interface{}{"serve": &serveTestConfig{}}
+	for _, args := range [][]string{nil, {"deploy"}, {"--port=1", "serve"}} {
+		if _, _, err := ProcessSubcommand(context.Background(), "", args, specs, subcommandTestOptions...); !errors.Is(err, ErrUnknownSubcommand) {
+			t.Errorf("Expected ErrUnknownSubcommand for %v, got %v", args, err)
+		}
+	}
+
+	_, _, err := ProcessSubcommand(context.Background(), "", []string{"serve", "-h"}, specs, subcommandTestOptions...)
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Expected flag.ErrHelp, got %v", err)
+	}
+}
+
+// TestLoaderReportArgs tests that the report of a caller-supplied flag set holds the positional arguments.
+func TestLoaderReportArgs(t *testing.T) {
+	var cfg serveTestConfig
+	report, err := newTestLoader([]string{"--port=1", "a", "b"}, nil).LoadWithReport(context.Background(), "", &cfg)
+	if err != nil {
+		t.Fatalf("LoadWithReport failed: %v", err)
+	}
+	if !reflect.DeepEqual(report.Args, []string{"a", "b"}) {
+		t.Errorf("Expected args [a b], got %v", report.Args)
+	}
+}
@@ -0,0 +1,315 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Formats rendered by WriteDoc.
+const (
+	// DocFormatUsage is an aligned --help table of flags, env vars, secrets and defaults.
+	DocFormatUsage = "usage"
+	// DocFormatMarkdown is a Markdown reference table of every field.
+	DocFormatMarkdown = "markdown"
+	// DocFormatDotenv is a commented .env.example file.
+	DocFormatDotenv = "dotenv"
+	// DocFormatYAML is a commented config.yaml.example file.
+	DocFormatYAML = "yaml"
+)
+
+// fieldDoc describes a field of a spec for the doc generators.
+type fieldDoc struct {
+	fieldSpec
+	Env         string
+	Type        string
+	Default     string
+	Required    bool
+	Description string
+	Constraints string
+}
+
+// describeFields returns the documentation of the fields of spec, in declaration order,
+// with the defaults of profile.
+func describeFields(prefix, profile string, spec interface{}) ([]fieldDoc, error) {
+	specValue := reflect.ValueOf(spec)
+	if specValue.Kind() != reflect.Ptr || specValue.IsNil() || specValue.Elem().Kind() != reflect.Struct {
+		return nil, errInvalidSpecification
+	}
+	var docs []fieldDoc
+	for _, fs := range collectFieldsOfCopy(specValue.Elem()) {
+		defaultValue, _ := profileDefault(fs.Field.Tag, profile)
+		doc := fieldDoc{
+			fieldSpec:   fs,
+			Type:        fs.Value.Type().String(),
+			Default:     defaultValue,
+			Required:    fs.Field.Tag.Get(TagRequired) == "true",
+			Description: fs.Field.Tag.Get(TagDescription),
+		}
+		if fs.EnvKey != "" {
+			doc.Env = strings.ToUpper(prefix + fs.EnvKey)
+		}
+		var constraints []string
+		for _, tag := range []string{TagMin, TagMax, TagLen, TagOneOf, TagRegex, TagURL, TagHostPort, TagFileExists} {
+			if value, ok := fs.Field.Tag.Lookup(tag); ok {
+				constraints = append(constraints, tag+"="+value)
+			}
+		}
+		doc.Constraints = strings.Join(constraints, " ")
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// WriteDoc renders the documentation of spec in format, one of the DocFormat constants.
+// The prefix is applied to environment variable names as by ProcessConfig.
+func WriteDoc(w io.Writer, format, prefix string, spec interface{}) error {
+	switch format {
+	case DocFormatUsage:
+		return WriteUsage(w, prefix, spec)
+	case DocFormatMarkdown:
+		return WriteMarkdown(w, prefix, spec)
+	case DocFormatDotenv:
+		return WriteDotenvExample(w, prefix, spec)
+	case DocFormatYAML:
+		return WriteYAMLExample(w, prefix, spec)
+	}
+	return fmt.Errorf("unsupported doc format %q (expected %s, %s, %s or %s)",
+		format, DocFormatUsage, DocFormatMarkdown, DocFormatDotenv, DocFormatYAML)
+}
+
+// WriteUsage writes an aligned table of the flags, environment variables and secrets
+// of spec, with their defaults and descriptions, e.g. for a flag.FlagSet's Usage func.
+func WriteUsage(w io.Writer, prefix string, spec interface{}) error {
+	return writeUsage(w, prefix, "", spec)
+}
+
+// WriteUsage writes the usage table of spec like the package-level WriteUsage, with
+// the defaults of the loader's active profile (`default.<profile>` tags).
+func (l *Loader) WriteUsage(w io.Writer, prefix string, spec interface{}) error {
+	return writeUsage(w, prefix, l.currentProfile(), spec)
+}
+
+// writeUsage writes the usage table of spec with the defaults of profile.
+func writeUsage(w io.Writer, prefix, profile string, spec interface{}) error {
+	docs, err := describeFields(prefix, profile, spec)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tENV\tSECRET\tDEFAULT\tREQUIRED\tDESCRIPTION")
+	for _, doc := range docs {
+		flagName := "-"
+		if doc.FlagName != "" {
+			flagName = "--" + doc.FlagName
+		}
+		required := ""
+		if doc.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			flagName, orDash(doc.Env), orDash(doc.SecretName), orDash(doc.Default), required, doc.Description)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	// Drop the padding of empty trailing columns.
+	lines := strings.SplitAfter(sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \n")
+		if strings.HasSuffix(line, "\n") {
+			lines[i] += "\n"
+		}
+	}
+	_, err = io.WriteString(w, strings.Join(lines, ""))
+	return err
+}
+
+// WriteMarkdown writes a Markdown reference table of the fields of spec.
+func WriteMarkdown(w io.Writer, prefix string, spec interface{}) error {
+	docs, err := describeFields(prefix, "", spec)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString("| Field | Type | Env | Flag | Secret | File key | Default | Required | Constraints | Description |\n")
+	sb.WriteString("|---|---|---|---|---|---|---|---|---|---|\n")
+	for _, doc := range docs {
+		flagName := ""
+		if doc.FlagName != "" {
+			flagName = "--" + doc.FlagName
+		}
+		required := ""
+		if doc.Required {
+			required = "yes"
+		}
+		cells := []string{
+			doc.Name, doc.Type, doc.Env, flagName, doc.SecretName, strings.Join(doc.FileKey, "."),
+			doc.Default, required, doc.Constraints, doc.Description,
+		}
+		for i, cell := range cells {
+			if cell != "" && i < len(cells)-1 {
+				cell = "`" + cell + "`"
+			}
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteDotenvExample writes a commented .env.example file with an entry per environment
+// variable of spec, set to its default. Entries without a default are commented out
+// unless required.
+func WriteDotenvExample(w io.Writer, prefix string, spec interface{}) error {
+	docs, err := describeFields(prefix, "", spec)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, doc := range docs {
+		if doc.Env == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		for _, line := range docComment(doc) {
+			sb.WriteString("# " + line + "\n")
+		}
+		entry := doc.Env + "=" + dotenvQuote(doc.Default)
+		if doc.Default == "" && !doc.Required {
+			entry = "# " + entry
+		}
+		sb.WriteString(entry + "\n")
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteYAMLExample writes a commented config.yaml.example file with the config file
+// keys of spec, nested as they are read by the loader, set to their defaults.
+func WriteYAMLExample(w io.Writer, _ string, spec interface{}) error {
+	docs, err := describeFields("", "", spec)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	var open []string // Keys of the nested maps being written
+	for _, doc := range docs {
+		if doc.FileKey == nil {
+			continue
+		}
+		parents := doc.FileKey[:len(doc.FileKey)-1]
+		common := 0
+		for common < len(open) && common < len(parents) && open[common] == parents[common] {
+			common++
+		}
+		open = open[:common]
+		for _, key := range parents[common:] {
+			sb.WriteString(strings.Repeat("  ", len(open)) + key + ":\n")
+			open = append(open, key)
+		}
+
+		indent := strings.Repeat("  ", len(open))
+		for _, line := range docComment(doc) {
+			sb.WriteString(indent + "# " + line + "\n")
+		}
+		entry := doc.FileKey[len(doc.FileKey)-1] + ":"
+		if value := yamlExampleValue(doc); value != "" {
+			entry += " " + value
+		}
+		if doc.Default == "" && !doc.Required {
+			entry = "# " + entry
+		}
+		sb.WriteString(indent + entry + "\n")
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// docComment returns the comment lines describing a field in example files.
+func docComment(doc fieldDoc) []string {
+	summary := doc.Name + " (" + doc.Type + ")"
+	if doc.Description != "" {
+		summary += ": " + doc.Description
+	}
+	lines := []string{summary}
+	var notes []string
+	if doc.Required {
+		notes = append(notes, "required")
+	}
+	if doc.Constraints != "" {
+		notes = append(notes, doc.Constraints)
+	}
+	if isSensitive(doc.fieldSpec) {
+		notes = append(notes, "sensitive")
+	}
+	if len(notes) > 0 {
+		lines = append(lines, strings.Join(notes, ", "))
+	}
+	return lines
+}
+
+// dotenvQuote quotes a .env value if it contains characters godotenv would interpret.
+func dotenvQuote(value string) string {
+	if strings.ContainsAny(value, " #\"'\\$\t\n") {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "$", `\$`).Replace(value) + `"`
+	}
+	return value
+}
+
+// yamlExampleValue renders the default of a field as a YAML value: slices as flow
+// sequences, maps as flow mappings and strings quoted as needed.
+func yamlExampleValue(doc fieldDoc) string {
+	if doc.Default == "" {
+		return ""
+	}
+	typ := doc.Value.Type()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	opts := valueOptionsFromTag(doc.Field.Tag)
+	switch {
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8:
+		parts := strings.Split(doc.Default, opts.separator)
+		for i, part := range parts {
+			parts[i] = yamlScalar(strings.TrimSpace(part))
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case typ.Kind() == reflect.Map:
+		entries := strings.Split(doc.Default, opts.separator)
+		for i, entry := range entries {
+			kv := strings.SplitN(entry, opts.kvSeparator, 2)
+			if len(kv) == 2 {
+				entries[i] = yamlScalar(strings.TrimSpace(kv[0])) + ": " + yamlScalar(strings.TrimSpace(kv[1]))
+			}
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	case typ.Kind() == reflect.String:
+		return yamlScalar(doc.Default)
+	}
+	return doc.Default
+}
+
+// yamlScalar renders a string as a YAML scalar, quoted if needed.
+func yamlScalar(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+package env
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type usageTestConfig struct {
+	Host    string        `env:"HOST" flag:"host" default:"localhost" description:"Address to listen on"`
+	Port    int           `env:"PORT" flag:"port" default:"8080" required:"true" min:"1" max:"65535"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Origins []string      `env:"ORIGINS" default:"a.com,b.com"`
+	DB      struct {
+		Password string `env:"PASSWORD" secret:"password" description:"Database password"`
+	} `prefix:"DB_"`
+}
+
+// TestWriteUsage tests the --help table.
+func TestWriteUsage(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteUsage(&sb, "APP_", &usageTestConfig{}); err != nil {
+		t.Fatalf("WriteUsage failed: %v", err)
+	}
+	want := `FLAG    ENV              SECRET       DEFAULT      REQUIRED  DESCRIPTION
+--host  APP_HOST         -            localhost              Address to listen on
+--port  APP_PORT         -            8080         yes
+-       APP_TIMEOUT      -            -
+-       APP_ORIGINS      -            a.com,b.com
+-       APP_DB_PASSWORD  db-password  -                      Database password
+`
+	if sb.String() != want {
+		t.Errorf("Unexpected usage:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+// TestWriteMarkdown tests the Markdown reference table.
+func TestWriteMarkdown(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteMarkdown(&sb, "", &usageTestConfig{}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	for _, want := range []string{
+		"| Field | Type | Env | Flag | Secret | File key | Default | Required | Constraints | Description |\n",
+		"| `Port` | `int` | `PORT` | `--port` |  | `port` | `8080` | `yes` | `min=1 max=65535` |  |\n",
+		"| `DB.Password` | `string` | `DB_PASSWORD` |  | `db-password` | `db.password` |  |  |  | Database password |\n",
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, sb.String())
+		}
+	}
+}
+
+// TestWriteDotenvExample tests the .env.example output.
+func TestWriteDotenvExample(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteDotenvExample(&sb, "", &usageTestConfig{}); err != nil {
+		t.Fatalf("WriteDotenvExample failed: %v", err)
+	}
+	want := `# Host (string): Address to listen on
+HOST=localhost
+
+# Port (int)
+# required, min=1 max=65535
+PORT=8080
+
+# Timeout (time.Duration)
+# TIMEOUT=
+
+# Origins ([]string)
+ORIGINS=a.com,b.com
+
+# DB.Password (string): Database password
+# sensitive
+# DB_PASSWORD=
+`
+	if sb.String() != want {
+		t.Errorf("Unexpected .env example:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+// TestWriteYAMLExample tests the config.yaml.example output and that it can be loaded back.
+func TestWriteYAMLExample(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteYAMLExample(&sb, "", &usageTestConfig{}); err != nil {
+		t.Fatalf("WriteYAMLExample failed: %v", err)
+	}
+	want := `# Host (string): Address to listen on
+host: localhost
+# Port (int)
+# required, min=1 max=65535
+port: 8080
+# Timeout (time.Duration)
+# timeout:
+# Origins ([]string)
+origins: [a.com, b.com]
+db:
+  # DB.Password (string): Database password
+  # sensitive
+  # password:
+`
+	if sb.String() != want {
+		t.Errorf("Unexpected YAML example:\n%s\nwant:\n%s", sb.String(), want)
+	}
+
+	path := writeTestFile(t, t.TempDir(), "config.yaml", sb.String())
+	values, err := readConfigFile(path)
+	if err != nil {
+		t.Fatalf("Generated YAML is invalid: %v", err)
+	}
+	if values["host"] != "localhost" || values["port"] != 8080 {
+		t.Errorf("Unexpected values read back: %v", values)
+	}
+}
+
+// TestWriteDocFormat tests that unknown formats are rejected.
+func TestWriteDocFormat(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteDoc(&sb, "html", "", &usageTestConfig{}); err == nil {
+		t.Errorf("Expected error for unsupported format, got nil")
+	}
+	if err := WriteDoc(&sb, DocFormatUsage, "", usageTestConfig{}); err != errInvalidSpecification {
+		t.Errorf("Expected errInvalidSpecification, got %v", err)
+	}
+}
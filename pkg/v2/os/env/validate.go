@@ -0,0 +1,249 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validator is implemented by specs with validation rules that tags cannot express,
+// such as rules spanning several fields. Validate is called after all fields are loaded
+// and checked.
+type Validator interface {
+	Validate() error
+}
+
+// validateField checks the constraint tags of a loaded field and returns the violations.
+// Fields that no source set (found is false) are not checked; TagRequired rejects those.
+// Values set explicitly are checked even if they are zero (e.g., PORT=0 against min:"1"),
+// except empty strings (e.g., LOG_URL=), which leave an optional field unset like a
+// missing value; TagRequired rejects them.
+func validateField(fs fieldSpec, found bool) []FieldError {
+	if !found {
+		return nil
+	}
+	v := fs.Value
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String && v.Len() == 0 {
+		return nil
+	}
+
+	var errs []FieldError
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, FieldError{
+			Field:    fs.Name,
+			RawValue: rawValue(fs, formatValue(v)),
+			Kind:     ErrValidation,
+			msg:      fmt.Sprintf("field %q: ", fs.Name) + fmt.Sprintf(format, args...),
+		})
+	}
+	tag := fs.Field.Tag
+	sensitive := isSensitive(fs)
+	quote := func(s string) string {
+		if sensitive {
+			return RedactedValue
+		}
+		return strconv.Quote(s)
+	}
+
+	if bound := tag.Get(TagMin); bound != "" {
+		if cmp, err := compareBound(v, bound); err != nil {
+			fail("invalid %s tag %q: %v", TagMin, bound, err)
+		} else if cmp < 0 {
+			fail("%s is less than min %s", describeValue(v, sensitive), bound)
+		}
+	}
+	if bound := tag.Get(TagMax); bound != "" {
+		if cmp, err := compareBound(v, bound); err != nil {
+			fail("invalid %s tag %q: %v", TagMax, bound, err)
+		} else if cmp > 0 {
+			fail("%s is greater than max %s", describeValue(v, sensitive), bound)
+		}
+	}
+	if want := tag.Get(TagLen); want != "" {
+		n, err := strconv.Atoi(want)
+		length, hasLength := valueLength(v)
+		switch {
+		case err != nil:
+			fail("invalid %s tag %q: %v", TagLen, want, err)
+		case !hasLength:
+			fail("invalid %s tag: %s has no length", TagLen, v.Type())
+		case length != n:
+			fail("length %d is not %d", length, n)
+		}
+	}
+
+	// The remaining constraints apply to the string form of the value,
+	// or of each element of slices and arrays.
+	elems := []string{formatValue(v)}
+	if (v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8) || v.Kind() == reflect.Array {
+		elems = make([]string, v.Len())
+		for i := range elems {
+			elems[i] = formatValue(v.Index(i))
+		}
+	}
+
+	if oneOf := tag.Get(TagOneOf); oneOf != "" {
+		allowed := strings.Fields(oneOf)
+		for _, elem := range elems {
+			if !containsString(allowed, elem) {
+				fail("%s is not one of [%s]", quote(elem), strings.Join(allowed, ", "))
+			}
+		}
+	}
+	if pattern := tag.Get(TagRegex); pattern != "" {
+		if re, err := regexp.Compile(pattern); err != nil {
+			fail("invalid %s tag %q: %v", TagRegex, pattern, err)
+		} else {
+			for _, elem := range elems {
+				if !re.MatchString(elem) {
+					fail("%s does not match %s", quote(elem), pattern)
+				}
+			}
+		}
+	}
+	if tag.Get(TagURL) == "true" {
+		for _, elem := range elems {
+			if u, err := url.Parse(elem); err != nil || u.Scheme == "" || u.Host == "" {
+				fail("%s is not an absolute URL", quote(elem))
+			}
+		}
+	}
+	if tag.Get(TagHostPort) == "true" {
+		for _, elem := range elems {
+			if err := checkHostPort(elem); err != nil {
+				fail("%s is not a host:port address", quote(elem))
+			}
+		}
+	}
+	if tag.Get(TagFileExists) == "true" {
+		for _, elem := range elems {
+			if _, err := os.Stat(elem); err != nil {
+				fail("file %s does not exist", quote(elem))
+			}
+		}
+	}
+	return errs
+}
+
+// compareBound compares a value with a min or max bound: numbers by value, durations
+// as durations and strings, slices and maps by length. It returns -1, 0 or +1.
+func compareBound(v reflect.Value, bound string) (int, error) {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(bound)
+		if err != nil {
+			return 0, err
+		}
+		return compareInt(v.Int(), int64(d)), nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b, err := strconv.ParseInt(bound, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareInt(v.Int(), b), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b, err := strconv.ParseUint(bound, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		switch x := v.Uint(); {
+		case x < b:
+			return -1, nil
+		case x > b:
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.Float32, reflect.Float64:
+		b, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return 0, err
+		}
+		switch x := v.Float(); {
+		case x < b:
+			return -1, nil
+		case x > b:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	length, ok := valueLength(v)
+	if !ok {
+		return 0, fmt.Errorf("cannot compare %s", v.Type())
+	}
+	b, err := strconv.Atoi(bound)
+	if err != nil {
+		return 0, err
+	}
+	return compareInt(int64(length), int64(b)), nil
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// valueLength returns the length of strings (in characters), slices, arrays and maps.
+func valueLength(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// describeValue renders a value in min/max violations: the length of strings and
+// collections, else the value unless it is sensitive.
+func describeValue(v reflect.Value, sensitive bool) string {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		length, _ := valueLength(v)
+		return fmt.Sprintf("length %d", length)
+	}
+	if sensitive {
+		return "value " + RedactedValue
+	}
+	return fmt.Sprintf("value %s", formatValue(v))
+}
+
+// checkHostPort checks that address is a host:port pair with a numeric port.
+// The host may be empty (e.g., ":8080").
+func checkHostPort(address string) error {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
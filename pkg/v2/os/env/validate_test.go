@@ -0,0 +1,182 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type validateTestConfig struct {
+	Port     int           `env:"PORT" min:"1" max:"65535"`
+	Timeout  time.Duration `env:"TIMEOUT" max:"1m"`
+	Level    string        `env:"LEVEL" oneof:"debug info warn error"`
+	Name     string        `env:"NAME" regex:"^[a-z][a-z0-9-]*$"`
+	Code     string        `env:"CODE" len:"3"`
+	Tags     []string      `env:"TAGS" min:"1" max:"2" oneof:"a b c"`
+	Endpoint string        `env:"ENDPOINT" url:"true"`
+	Addr     string        `env:"ADDR" hostport:"true"`
+	CertFile string        `env:"CERT_FILE" file_exists:"true"`
+	Token    string        `env:"TOKEN" len:"8" sensitive:"true"`
+	Optional int           `env:"OPTIONAL" min:"10"`
+}
+
+// TestValidateConstraints tests that each constraint tag accepts valid values.
+func TestValidateConstraints(t *testing.T) {
+	t.Parallel()
+	cert := writeTestFile(t, t.TempDir(), "cert.pem", "cert")
+	l := newTestLoader(nil, map[string]string{
+		"PORT":      "8080",
+		"TIMEOUT":   "30s",
+		"LEVEL":     "info",
+		"NAME":      "my-app",
+		"CODE":      "abc",
+		"TAGS":      "a,c",
+		"ENDPOINT":  "https://example.com/api",
+		"ADDR":      "localhost:9090",
+		"CERT_FILE": cert,
+		"TOKEN":     "12345678",
+	})
+
+	var cfg validateTestConfig
+	if err := l.Load(context.Background(), "", &cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+}
+
+// TestValidateViolations tests that all violations are collected into one error.
+func TestValidateViolations(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{
+		"PORT":      "70000",
+		"TIMEOUT":   "2m",
+		"LEVEL":     "trace",
+		"NAME":      "My App",
+		"CODE":      "abcd",
+		"TAGS":      "a,b,d",
+		"ENDPOINT":  "example.com",
+		"ADDR":      "localhost",
+		"CERT_FILE": filepath.Join(t.TempDir(), "missing.pem"),
+		"TOKEN":     "s3cret",
+	})
+
+	var cfg validateTestConfig
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil {
+		t.Fatalf("Expected validation errors, got nil")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		`field "Port": value 70000 is greater than max 65535`,
+		`field "Timeout": value 2m0s is greater than max 1m`,
+		`field "Level": "trace" is not one of [debug, info, warn, error]`,
+		`field "Name": "My App" does not match`,
+		`field "Code": length 4 is not 3`,
+		`field "Tags": length 3 is greater than max 2`,
+		`field "Tags": "d" is not one of [a, b, c]`,
+		`field "Endpoint": "example.com" is not an absolute URL`,
+		`field "Addr": "localhost" is not a host:port address`,
+		`field "CertFile": file`,
+		`field "Token": length 6 is not 8`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected error to contain %q, got:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "Optional") {
+		t.Errorf("Expected unset fields not to be validated, got:\n%s", msg)
+	}
+}
+
+// TestValidateExplicitZero tests that zero values set by a source are checked against constraints.
+func TestValidateExplicitZero(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"PORT": "0", "RETRIES": "0"})
+
+	var cfg struct {
+		Port    int `env:"PORT" min:"1" max:"65535"`
+		Retries int `env:"RETRIES" max:"-1"`
+		Unset   int `env:"UNSET" min:"1"`
+	}
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil {
+		t.Fatalf("Expected validation errors, got nil")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		`field "Port": value 0 is less than min 1`,
+		`field "Retries": value 0 is greater than max -1`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected error to contain %q, got:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "Unset") {
+		t.Errorf("Expected unset fields not to be validated, got:\n%s", msg)
+	}
+}
+
+// TestValidateEmptyString tests that empty strings are not checked against constraints.
+func TestValidateEmptyString(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"LOG_URL": "", "ADDR": "", "NAME": ""})
+
+	var cfg struct {
+		LogURL string `env:"LOG_URL" url:"true" regex:"^https://"`
+		Addr   string `env:"ADDR" hostport:"true"`
+		Name   string `env:"NAME" required:"true" min:"3"`
+	}
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil {
+		t.Fatalf("Expected a required error, got nil")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, `field "Name" is required`) {
+		t.Errorf("Expected error to contain the required Name, got:\n%s", msg)
+	}
+	for _, unwanted := range []string{"LogURL", "Addr", "less than min"} {
+		if strings.Contains(msg, unwanted) {
+			t.Errorf("Expected empty strings not to be validated, got:\n%s", msg)
+		}
+	}
+}
+
+// TestValidateInvalidTag tests that malformed constraint tags are reported.
+func TestValidateInvalidTag(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"PORT": "80"})
+
+	var cfg struct {
+		Port int `env:"PORT" min:"one"`
+	}
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil || !strings.Contains(err.Error(), `invalid min tag "one"`) {
+		t.Errorf("Expected invalid min tag error, got %v", err)
+	}
+}
+
+type validatorTestConfig struct {
+	Min int `env:"MIN"`
+	Max int `env:"MAX"`
+}
+
+func (c *validatorTestConfig) Validate() error {
+	if c.Min > c.Max {
+		return errors.New("MIN must not exceed MAX")
+	}
+	return nil
+}
+
+// TestValidatorHook tests that the Validate method is called after loading.
+func TestValidatorHook(t *testing.T) {
+	t.Parallel()
+	l := newTestLoader(nil, map[string]string{"MIN": "5", "MAX": "1"})
+
+	var cfg validatorTestConfig
+	err := l.Load(context.Background(), "", &cfg)
+	if err == nil || !strings.Contains(err.Error(), "validation failed: MIN must not exceed MAX") {
+		t.Errorf("Expected Validate error, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package env
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default intervals of a Watcher.
+const (
+	DefaultPollInterval          = 5 * time.Second
+	DefaultSecretRefreshInterval = 5 * time.Minute
+)
+
+// ChangeEvent is delivered by a Watcher after a reload.
+type ChangeEvent[T any] struct {
+	// Old is the spec before the reload.
+	Old *T
+	// New is the spec after the reload; nil if the reload failed.
+	New *T
+	// Changed lists the dotted paths of the fields whose value changed.
+	Changed []string
+	// Pinned lists the fields tagged `reload:"false"` whose sources changed;
+	// they keep their old value.
+	Pinned []string
+	// Report describes the reloaded spec.
+	Report *Report
+	// Err is set if the reload failed; the current spec is left in place.
+	Err error
+}
+
+// WatchOption configures a Watcher.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	pollInterval          time.Duration
+	secretRefreshInterval time.Duration
+}
+
+// WithPollInterval sets how often config and .env files are checked for changes.
+// Default is DefaultPollInterval.
+func WithPollInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) { o.pollInterval = d }
+}
+
+// WithSecretRefreshInterval sets how often secrets are re-fetched; zero disables it.
+// Default is DefaultSecretRefreshInterval.
+func WithSecretRefreshInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) { o.secretRefreshInterval = d }
+}
+
+// Watcher keeps a spec of type T up to date with its sources.
+//
+// The spec is never modified in place: each reload loads a new copy, which replaces
+// the current one atomically. Callers must treat the specs returned by Current and
+// delivered in ChangeEvents as read-only.
+type Watcher[T any] struct {
+	loader *Loader
+	prefix string
+	opts   watchOptions
+
+	current atomic.Pointer[T]
+	changes chan ChangeEvent[T]
+
+	// mu serializes reloads.
+	mu     sync.Mutex
+	files  []string
+	stats  map[string]fileStat
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// fileStat is the part of a file's metadata used to detect changes.
+type fileStat struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// Watch loads a spec of type T with l (the loader behind ProcessConfig if nil) and
+// starts watching its sources: config and .env files are polled for changes and
+// secrets are re-fetched periodically. Each reload that changes the spec is
+// delivered on Changes, as is each failed reload.
+//
+// Fields tagged `reload:"false"` keep the value of the initial load.
+//
+// Watch returns an error if the initial load fails. The watcher stops when ctx is
+// done or Stop is called.
+func Watch[T any](ctx context.Context, l *Loader, prefix string, opts ...WatchOption) (*Watcher[T], error) {
+	if l == nil {
+		l = defaultLoader
+	}
+	w := &Watcher[T]{
+		loader: l,
+		prefix: prefix,
+		opts: watchOptions{
+			pollInterval:          DefaultPollInterval,
+			secretRefreshInterval: DefaultSecretRefreshInterval,
+		},
+		changes: make(chan ChangeEvent[T], 16),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&w.opts)
+	}
+
+	spec := new(T)
+	report, err := l.LoadWithReport(ctx, prefix, spec)
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(spec)
+	w.files = report.Files
+	w.stats = statFiles(w.files)
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	go w.run(ctx)
+	return w, nil
+}
+
+// Current returns the current spec.
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load()
+}
+
+// Changes returns the channel change events are delivered on.
+// It is closed when the watcher stops.
+func (w *Watcher[T]) Changes() <-chan ChangeEvent[T] {
+	return w.changes
+}
+
+// Stop stops the watcher and waits for it to exit.
+func (w *Watcher[T]) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// run polls the sources until ctx is done.
+func (w *Watcher[T]) run(ctx context.Context) {
+	defer close(w.done)
+	defer close(w.changes)
+
+	poll := time.NewTicker(w.opts.pollInterval)
+	defer poll.Stop()
+	var refresh <-chan time.Time
+	if w.opts.secretRefreshInterval > 0 {
+		ticker := time.NewTicker(w.opts.secretRefreshInterval)
+		defer ticker.Stop()
+		refresh = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			if !w.filesChanged() {
+				continue
+			}
+		case <-refresh:
+		}
+		if ev, ok := w.reload(ctx); ok {
+			select {
+			case w.changes <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Reload reloads the spec immediately, as if its sources had changed.
+// It returns the resulting event, if any, instead of delivering it on Changes.
+func (w *Watcher[T]) Reload(ctx context.Context) (ChangeEvent[T], bool) {
+	return w.reload(ctx)
+}
+
+// reload loads a new copy of the spec and swaps it in if it changed.
+// It reports whether the resulting event should be delivered.
+func (w *Watcher[T]) reload(ctx context.Context) (ChangeEvent[T], bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	old := w.current.Load()
+	spec := new(T)
+	report, err := w.loader.LoadWithReport(ctx, w.prefix, spec)
+	if report != nil {
+		w.files = report.Files
+	}
+	w.stats = statFiles(w.files)
+	if err != nil {
+		return ChangeEvent[T]{Old: old, Report: report, Err: err}, true
+	}
+
+	ev := ChangeEvent[T]{Old: old, New: spec, Report: report}
+	oldFields := collectFields(reflect.ValueOf(old).Elem(), namePrefix{})
+	newFields := collectFields(reflect.ValueOf(spec).Elem(), namePrefix{})
+	for i, nf := range newFields {
+		of := oldFields[i]
+		if reflect.DeepEqual(of.Value.Interface(), nf.Value.Interface()) {
+			continue
+		}
+		if nf.Field.Tag.Get(TagReload) == "false" {
+			nf.Value.Set(of.Value)
+			if fr := report.Field(nf.Name); fr != nil && !fr.Sensitive {
+				fr.Value = formatValue(nf.Value)
+			}
+			ev.Pinned = append(ev.Pinned, nf.Name)
+			continue
+		}
+		ev.Changed = append(ev.Changed, nf.Name)
+	}
+	if len(ev.Changed) == 0 {
+		return ev, false
+	}
+	w.current.Store(spec)
+	return ev, true
+}
+
+// filesChanged reports whether any watched file was created, removed or modified
+// since the last load.
+func (w *Watcher[T]) filesChanged() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !reflect.DeepEqual(w.stats, statFiles(w.files))
+}
+
+// statFiles returns the current metadata of the files.
+func statFiles(paths []string) map[string]fileStat {
+	stats := make(map[string]fileStat, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			stats[path] = fileStat{}
+			continue
+		}
+		stats[path] = fileStat{exists: true, size: info.Size(), modTime: info.ModTime()}
+	}
+	return stats
+}
@@ -0,0 +1,118 @@
+package env
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type watchTestConfig struct {
+	Name     string `yaml:"name"`
+	Port     int    `yaml:"port" reload:"false"`
+	Password string `secret:"db-password"`
+}
+
+// receiveChange waits for the next change event from w.
+func receiveChange(t *testing.T, w *Watcher[watchTestConfig]) ChangeEvent[watchTestConfig] {
+	t.Helper()
+	select {
+	case ev := <-w.Changes():
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for a change event")
+		return ChangeEvent[watchTestConfig]{}
+	}
+}
+
+// TestWatchFileChange tests that edits to a config file are reloaded and that
+// fields tagged reload:"false" keep their initial value.
+func TestWatchFileChange(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "config.yaml", "name: a\nport: 80\n")
+	secrets := NewMemorySecretProvider(map[string]string{"db-password": "one"})
+	l := newTestLoader(nil, nil, WithConfigFiles(path), WithSecretProvider(secrets))
+
+	w, err := Watch[watchTestConfig](context.Background(), l, "",
+		WithPollInterval(10*time.Millisecond), WithSecretRefreshInterval(0))
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	initial := w.Current()
+	if initial.Name != "a" || initial.Port != 80 || initial.Password != "one" {
+		t.Fatalf("Unexpected initial config: %+v", *initial)
+	}
+
+	writeTestFile(t, "", path, "name: bb\nport: 9090\n")
+	ev := receiveChange(t, w)
+	if ev.Err != nil {
+		t.Fatalf("Reload failed: %v", ev.Err)
+	}
+	if !reflect.DeepEqual(ev.Changed, []string{"Name"}) {
+		t.Errorf("Expected changed fields [Name], got %v", ev.Changed)
+	}
+	if !reflect.DeepEqual(ev.Pinned, []string{"Port"}) {
+		t.Errorf("Expected pinned fields [Port], got %v", ev.Pinned)
+	}
+	if ev.Old != initial || ev.New != w.Current() {
+		t.Errorf("Expected event to carry the old and current specs")
+	}
+	if current := w.Current(); current.Name != "bb" || current.Port != 80 {
+		t.Errorf("Expected Name 'bb' and pinned Port 80, got %+v", *current)
+	}
+	if initial.Name != "a" {
+		t.Errorf("Expected the old spec to be left unmodified, got Name %q", initial.Name)
+	}
+}
+
+// TestWatchSecretRefresh tests that rotated secrets are picked up on reload.
+func TestWatchSecretRefresh(t *testing.T) {
+	secrets := NewMemorySecretProvider(map[string]string{"db-password": "one"})
+	l := newTestLoader(nil, nil, WithSecretProvider(secrets))
+
+	w, err := Watch[watchTestConfig](context.Background(), l, "",
+		WithPollInterval(time.Hour), WithSecretRefreshInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+
+	secrets.Set("db-password", "two")
+	ev := receiveChange(t, w)
+	if !reflect.DeepEqual(ev.Changed, []string{"Password"}) {
+		t.Errorf("Expected changed fields [Password], got %v", ev.Changed)
+	}
+	if fr := ev.Report.Field("Password"); fr == nil || fr.Value != RedactedValue {
+		t.Errorf("Expected redacted Password in report, got %+v", fr)
+	}
+	if w.Current().Password != "two" {
+		t.Errorf("Expected Password 'two', got %q", w.Current().Password)
+	}
+}
+
+// TestWatchReloadError tests that a failed reload is reported and keeps the current spec.
+func TestWatchReloadError(t *testing.T) {
+	secrets := NewMemorySecretProvider(map[string]string{"db-password": "one"})
+	l := newTestLoader(nil, nil, WithSecretProvider(secrets))
+
+	w, err := Watch[watchTestConfig](context.Background(), l, "",
+		WithPollInterval(time.Hour), WithSecretRefreshInterval(0))
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	initial := w.Current()
+
+	if _, ok := w.Reload(context.Background()); ok {
+		t.Errorf("Expected no event when nothing changed")
+	}
+
+	secrets.Delete("db-password")
+	ev, ok := w.Reload(context.Background())
+	if !ok || ev.Err == nil {
+		t.Fatalf("Expected a reload error event, got %+v", ev)
+	}
+	if w.Current() != initial {
+		t.Errorf("Expected the current spec to be kept after a failed reload")
+	}
+}